@@ -0,0 +1,80 @@
+package env
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultBoolValues returns the values accepted by lenient booleans, enabled
+// with Options.LenientBools or the `lenientBool` tag option, when
+// Options.BoolValues is not set. Values are matched regardless of their case.
+func DefaultBoolValues() map[string]bool {
+	return map[string]bool{
+		"1": true, "t": true, "true": true, "y": true, "yes": true,
+		"on": true, "enable": true, "enabled": true,
+		"0": false, "f": false, "false": false, "n": false, "no": false,
+		"off": false, "disable": false, "disabled": false,
+	}
+}
+
+// parseLenientBool parses value with the given vocabulary, whose values are in
+// lower case, regardless of its case.
+func parseLenientBool(value string, values map[string]bool) (bool, error) {
+	if b, ok := values[strings.ToLower(value)]; ok {
+		return b, nil
+	}
+
+	return false, fmt.Errorf("invalid boolean %q, expected one of %q", value, sortedBoolValues(values))
+}
+
+func sortedBoolValues(values map[string]bool) []string {
+	accepted := make([]string, 0, len(values))
+	for v := range values {
+		accepted = append(accepted, v)
+	}
+	sort.Strings(accepted)
+	return accepted
+}
+
+// normalizeBoolValues returns a copy of values with its values in lower case,
+// so they can be matched regardless of their case. It returns an error if
+// values only differing in case mean different booleans.
+func normalizeBoolValues(values map[string]bool) (map[string]bool, error) {
+	result := make(map[string]bool, len(values))
+	for _, v := range sortedBoolValues(values) {
+		lower := strings.ToLower(v)
+		if b, ok := result[lower]; ok && b != values[v] {
+			return nil, fmt.Errorf("BoolValues maps %q to both true and false, regardless of its case", lower)
+		}
+		result[lower] = values[v]
+	}
+	return result, nil
+}
+
+// encodeBoolValues encodes values as a string, with encodeStringMap.
+func encodeBoolValues(values map[string]bool) string {
+	m := make(map[string]string, len(values))
+	for v, b := range values {
+		m[v] = strconv.FormatBool(b)
+	}
+	return encodeStringMap(m)
+}
+
+// decodeBoolValues decodes a string encoded with encodeBoolValues.
+func decodeBoolValues(s string) map[string]bool {
+	m := decodeStringMap(s)
+	values := make(map[string]bool, len(m))
+	for v, b := range m {
+		values[v] = b == "true"
+	}
+	return values
+}
+
+func boolValues(opts Options) map[string]bool {
+	if opts.BoolValues != nil {
+		return opts.BoolValues
+	}
+	return DefaultBoolValues()
+}
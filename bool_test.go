@@ -0,0 +1,111 @@
+package env
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLenientBools(t *testing.T) {
+	type Config struct {
+		Yes      bool            `env:"YES"`
+		No       bool            `env:"NO"`
+		On       *bool           `env:"ON"`
+		Enabled  bool            `env:"ENABLED"`
+		Upper    bool            `env:"UPPER"`
+		Flags    []bool          `env:"FLAGS"`
+		Features map[string]bool `env:"FEATURES"`
+		Default  bool            `env:"DEFAULT" envDefault:"y"`
+		Optional Optional[bool]  `env:"OPTIONAL"`
+	}
+
+	cfg, err := ParseAsWithOptions[Config](Options{
+		LenientBools: true,
+		Environment: map[string]string{
+			"YES":      "yes",
+			"NO":       "no",
+			"ON":       "on",
+			"ENABLED":  "enabled",
+			"UPPER":    "TRUE",
+			"FLAGS":    "y,n,1,off",
+			"FEATURES": "a:on,b:disabled",
+			"OPTIONAL": "off",
+		},
+	})
+	isNoErr(t, err)
+	isTrue(t, cfg.Yes)
+	isFalse(t, cfg.No)
+	isTrue(t, *cfg.On)
+	isTrue(t, cfg.Enabled)
+	isTrue(t, cfg.Upper)
+	isEqual(t, []bool{true, false, true, false}, cfg.Flags)
+	isEqual(t, map[string]bool{"a": true, "b": false}, cfg.Features)
+	isTrue(t, cfg.Default)
+	v, ok := cfg.Optional.Get()
+	isTrue(t, ok)
+	isFalse(t, v)
+}
+
+func TestLenientBoolTagOption(t *testing.T) {
+	type Config struct {
+		Lenient bool `env:"LENIENT,lenientBool"`
+		Strict  bool `env:"STRICT"`
+	}
+
+	cfg, err := ParseAsWithOptions[Config](Options{Environment: map[string]string{"LENIENT": "on"}})
+	isNoErr(t, err)
+	isTrue(t, cfg.Lenient)
+
+	_, err = ParseAsWithOptions[Config](Options{Environment: map[string]string{"STRICT": "on"}})
+	isErrorWithMessage(t, err, `env: parse error on field "Strict" of type "bool": strconv.ParseBool: parsing "on": invalid syntax`)
+
+	params, err := GetFieldParamsWithOptions(&Config{}, Options{BoolValues: map[string]bool{"Oui": true, "NON": false}})
+	isNoErr(t, err)
+	isTrue(t, params[0].LenientBool)
+	isEqual(t, map[string]bool{"oui": true, "non": false}, params[0].BoolValues())
+	isFalse(t, params[1].LenientBool)
+	isEqual(t, map[string]bool(nil), params[1].BoolValues())
+}
+
+func TestLenientBoolsCustomValues(t *testing.T) {
+	type Config struct {
+		Debug bool `env:"DEBUG,lenientBool"`
+	}
+
+	opts := Options{
+		BoolValues:  map[string]bool{"oui": true, "NON": false, "Non": false},
+		Environment: map[string]string{"DEBUG": "Oui"},
+	}
+	cfg, err := ParseAsWithOptions[Config](opts)
+	isNoErr(t, err)
+	isTrue(t, cfg.Debug)
+
+	opts.Environment = map[string]string{"DEBUG": "yes"}
+	_, err = ParseAsWithOptions[Config](opts)
+	isErrorWithMessage(t, err, `env: parse error on field "Debug" of type "bool": invalid boolean "yes", expected one of ["non" "oui"]`)
+	isTrue(t, errors.Is(err, ParseError{}))
+}
+
+func TestLenientBoolsErrors(t *testing.T) {
+	type Config struct {
+		Debug bool   `env:"DEBUG"`
+		Flags []bool `env:"FLAGS" envDefault:"yes,maybe"`
+	}
+
+	_, err := ParseAsWithOptions[Config](Options{
+		LenientBools: true,
+		BoolValues:   map[string]bool{"yes": true, "no": false},
+		Environment:  map[string]string{"DEBUG": "1"},
+	})
+	isErrorWithMessage(t, err, `env: parse error on field "Debug" of type "bool": invalid boolean "1", expected one of ["no" "yes"]; `+
+		`parse error on field "Flags" of type "[]bool": invalid boolean "maybe", expected one of ["no" "yes"]`)
+
+	_, err = ParseAsWithOptions[Config](Options{
+		LenientBools: true,
+		BoolValues:   map[string]bool{"yes": true, "Yes": false},
+	})
+	isErrorWithMessage(t, err, `env: BoolValues maps "yes" to both true and false, regardless of its case`)
+
+	err = Check[Config](Options{LenientBools: true})
+	isErrorWithMessage(t, err, `env: parse error on field "Flags" of type "[]bool": invalid boolean "maybe", expected one of `+
+		`["0" "1" "disable" "disabled" "enable" "enabled" "f" "false" "n" "no" "off" "on" "t" "true" "y" "yes"]`)
+}
@@ -0,0 +1,209 @@
+package env
+
+import (
+	"encoding"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Check verifies that T can be parsed with opts, without reading the
+// environment: all its fields must have a parser (a builtin one, one in
+// opts.FuncMap, or encoding.TextUnmarshaler), including the items of slices
+// and the keys and values of maps, all their tag options must be supported,
+// and all their `envDefault` values, including the ones of profiles, must be
+// parsed without errors.
+//
+// It does not read any variable: opts.Environment, opts.Dirs and
+// opts.ProfileKey are ignored, and the files of `file` fields are not opened.
+//
+// It is meant to be called from tests, so broken configurations are caught
+// before deployment:
+//
+//	func TestConfig(t *testing.T) {
+//		if err := env.Check[Config](env.Options{}); err != nil {
+//			t.Fatal(err)
+//		}
+//	}
+func Check[T any](opts Options) error {
+	opts.Environment = map[string]string{}
+	opts.Dirs = nil
+	opts.ProfileKey = ""
+
+	opts, err := customOptions(opts)
+	if err != nil {
+		return err
+	}
+
+	var t T
+	return parseInternal(&t, checkField, opts)
+}
+
+// checkField is a processFieldFn that checks a field can be parsed.
+func checkField(refField reflect.Value, refTypeField reflect.StructField, opts Options, fieldParams FieldParams) error {
+	if fieldParams.OwnKey == "" {
+		return checkNested(refField, refTypeField, opts, fieldParams)
+	}
+
+	path := fieldPath(refTypeField, opts)
+	typ := refTypeField.Type
+
+	if variants, ok := opts.Variants[typ]; ok && typ.Kind() == reflect.Interface {
+		var agrErr AggregateError
+		for _, value := range fieldDefaults(fieldParams) {
+			if _, ok := variants[value]; !ok {
+				agrErr.Errors = append(agrErr.Errors, newUnknownVariantError(fieldParams.Key, path, value, variantNames(variants)))
+			}
+		}
+		for _, name := range variantNames(variants) {
+			variantOpts := optionsWithEnvPrefix(refTypeField, opts)
+			variantOpts.Prefix += RelaxedKeyMatcher(name) + string(underscore)
+			if err := doParse(reflect.New(variants[name]).Elem(), checkField, variantOpts); err != nil {
+				agrErr.Errors = append(agrErr.Errors, err.(AggregateError).Errors...)
+			}
+		}
+		if len(agrErr.Errors) == 0 {
+			return nil
+		}
+		return agrErr
+	}
+
+	if !hasParser(typ, opts.FuncMap) {
+		if isStructOrStructPtr(typ) || isSliceOfStructs(refTypeField) {
+			// Not a value, but a nested struct with a key, e.g. with
+			// UseFieldNameByDefault.
+			return checkNested(refField, refTypeField, opts, fieldParams)
+		}
+		return withField(newNoParserError(refTypeField), path, fieldParams.Key, "")
+	}
+
+	// Separators are only built when a value is set, so check them even if
+	// there is no default value.
+	if _, _, err := Separators(refTypeField, opts.FuncMap); err != nil {
+		sf := refTypeField
+		sf.Type = ValueType(sf.Type)
+		return withField(newParseError(sf, err), path, fieldParams.Key, "")
+	}
+
+	var agrErr AggregateError
+	for _, value := range fieldDefaults(fieldParams) {
+		if err := set(reflect.New(typ).Elem(), refTypeField, value, opts.FuncMap, fieldParams); err != nil {
+			if fieldParams.Sensitive {
+				err, value = redact(err, value), ""
+			}
+			agrErr.Errors = append(agrErr.Errors, withField(err, path, fieldParams.Key, value))
+		}
+	}
+	if len(agrErr.Errors) == 0 {
+		return nil
+	}
+	return agrErr
+}
+
+// checkNested checks the structs that are only parsed when the environment
+// has variables for them: nil pointers to structs and slices of structs.
+func checkNested(refField reflect.Value, refTypeField reflect.StructField, opts Options, fieldParams FieldParams) error {
+	typ := refTypeField.Type
+	if typ.Kind() == reflect.Ptr && typ.Elem().Kind() == reflect.Struct && refField.IsNil() && !fieldParams.Init {
+		return doParse(reflect.New(typ.Elem()).Elem(), checkField, optionsWithEnvPrefix(refTypeField, opts))
+	}
+	if isSliceOfStructs(refTypeField) {
+		if typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		sliceOpts := optionsWithEnvPrefix(refTypeField, opts)
+		if sliceOpts.Prefix != "" && !strings.HasSuffix(sliceOpts.Prefix, string(underscore)) {
+			sliceOpts.Prefix += string(underscore)
+		}
+		return doParse(reflect.New(typ.Elem()).Elem(), checkField, optionsWithSliceEnvPrefix(sliceOpts, 0, indexSeparator(refTypeField, opts)))
+	}
+	return nil
+}
+
+func isStructOrStructPtr(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ.Kind() == reflect.Struct
+}
+
+// fieldDefaults returns the default values of a field that can be checked:
+// the ones that are not empty, file paths, or expanded from other variables.
+func fieldDefaults(fieldParams FieldParams) []string {
+	if fieldParams.LoadFile || fieldParams.Expand {
+		return nil
+	}
+
+	var values []string
+	if fieldParams.HasDefaultValue && fieldParams.DefaultValue != "" {
+		values = append(values, fieldParams.DefaultValue)
+	}
+	profileDefaults := fieldParams.ProfileDefaults()
+	for _, profile := range sortedKeys(profileDefaults) {
+		if value := profileDefaults[profile]; value != "" && value != fieldParams.DefaultValue {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// hasParser reports whether set can parse values of typ.
+func hasParser(typ reflect.Type, funcMap map[reflect.Type]ParserFunc) bool {
+	if w := asWrapper(reflect.New(typ).Elem()); w != nil {
+		return hasParser(w.wrapped().Type(), funcMap)
+	}
+	if typ.Kind() == reflect.Ptr {
+		// The values of slices, arrays and maps behind a pointer are not
+		// split, so they need a parser of their own.
+		return hasElemParser(typ.Elem(), funcMap)
+	}
+	if hasElemParser(typ, funcMap) {
+		return true
+	}
+
+	switch typ.Kind() {
+	case reflect.Slice, reflect.Array:
+		elem := typ.Elem()
+		if isNestedCollection(elem, funcMap) {
+			return hasParser(elem, funcMap)
+		}
+		if elem.Kind() == reflect.Ptr {
+			elem = elem.Elem()
+		}
+		return hasElemParser(elem, funcMap)
+	case reflect.Map:
+		elem := typ.Elem()
+		if isNestedCollection(elem, funcMap) {
+			return hasElemParser(typ.Key(), funcMap) && hasParser(elem, funcMap)
+		}
+		if elem.Kind() == reflect.Ptr {
+			elem = elem.Elem()
+		}
+		return hasElemParser(typ.Key(), funcMap) && hasElemParser(elem, funcMap)
+	}
+	return false
+}
+
+func hasElemParser(typ reflect.Type, funcMap map[reflect.Type]ParserFunc) bool {
+	if _, ok := reflect.New(typ).Interface().(encoding.TextUnmarshaler); ok {
+		return true
+	}
+	return hasKindParser(typ, funcMap)
+}
+
+func hasKindParser(typ reflect.Type, funcMap map[reflect.Type]ParserFunc) bool {
+	if _, ok := funcMap[typ]; ok {
+		return true
+	}
+	_, ok := defaultBuiltInParsers[typ.Kind()]
+	return ok
+}
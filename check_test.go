@@ -0,0 +1,201 @@
+package env
+
+import (
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCheck(t *testing.T) {
+	type Database struct {
+		Port int `env:"PORT" envDefault:"5432"`
+	}
+
+	type Worker struct {
+		Queue string        `env:"QUEUE"`
+		Delay time.Duration `env:"DELAY" envDefault:"1s"`
+	}
+
+	type Config struct {
+		Host     string            `env:"HOST,required"`
+		Port     int               `env:"PORT" envDefault:"8080" envDefault.production:"80"`
+		Endpoint url.URL           `env:"ENDPOINT" envDefault:"https://example.com"`
+		Level    string            `env:"LEVEL" envDefault:"info" envEnum:"debug,info"`
+		Ports    []int             `env:"PORTS" envDefault:"80,443"`
+		Labels   map[string]string `env:"LABELS" envDefault:"a:b"`
+		Addr     *level            `env:"ADDR" envDefault:"debug"`
+		Name     Optional[string]  `env:"NAME"`
+		Token    Secret[[]byte]    `env:"TOKEN"`
+		Cert     int               `env:"CERT,file" envDefault:"/etc/cert"`
+		Path     int               `env:"EXPANDED,expand" envDefault:"${PORT}"`
+		Database Database          `envPrefix:"DB_"`
+		Replica  *Database         `envPrefix:"REPLICA_"`
+		Workers  []Worker          `envPrefix:"WORKERS"`
+		Ignored  chan int          `env:"-"`
+	}
+
+	isNoErr(t, Check[Config](Options{}))
+	isNoErr(t, Check[Config](Options{UseFieldNameByDefault: true}))
+}
+
+type level int
+
+func (l *level) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "debug":
+		*l = 0
+	case "info":
+		*l = 1
+	default:
+		return errors.New("unknown level")
+	}
+	return nil
+}
+
+func TestCheckErrors(t *testing.T) {
+	type Database struct {
+		Port int `env:"PORT" envDefault:"nope"`
+	}
+
+	type Worker struct {
+		Delay time.Duration `env:"DELAY" envDefault:"1"`
+	}
+
+	type Config struct {
+		Port     int               `env:"PORT" envDefault:"8080" envDefault.production:"eighty"`
+		Ports    []int             `env:"PORTS" envDefault:"80,x"`
+		Labels   map[string]int    `env:"LABELS" envDefault:"a"`
+		Addr     *level            `env:"ADDR" envDefault:"nope"`
+		Count    Optional[uint8]   `env:"COUNT" envDefault:"256"`
+		Token    Secret[int]       `env:"TOKEN" envDefault:"s3cr3t"`
+		Ch       chan int          `env:"CH"`
+		Chans    []chan int        `env:"CHANS"`
+		Funcs    map[string]func() `env:"FUNCS"`
+		Host     string            `env:"HOST,nope"`
+		Database *Database         `envPrefix:"DB_"`
+		Workers  []Worker          `envPrefix:"WORKERS"`
+	}
+
+	err := Check[Config](Options{})
+	isErrorWithMessage(t, err, `env: parse error on field "Port" of type "int": strconv.ParseInt: parsing "eighty": invalid syntax; `+
+		`parse error on field "Ports" of type "[]int": strconv.ParseInt: parsing "x": invalid syntax; `+
+		`parse error on field "Labels" of type "map[string]int": "a" should be in "key:value" format; `+
+		`parse error on field "Addr" of type "*env.level": unknown level; `+
+		`parse error on field "Count" of type "uint8": strconv.ParseUint: parsing "256": value out of range; `+
+		`parse error on field "Token" of type "int": strconv.ParseInt: parsing "[REDACTED]": invalid syntax; `+
+		`no parser found for field "Ch" of type "chan int"; `+
+		`no parser found for field "Chans" of type "[]chan int"; `+
+		`no parser found for field "Funcs" of type "map[string]func()"; `+
+		`tag option "nope" not supported; `+
+		`parse error on field "Port" of type "int": strconv.ParseInt: parsing "nope": invalid syntax; `+
+		`parse error on field "Delay" of type "time.Duration": unable to parse duration: time: missing unit in duration "1"`)
+
+	var agrErr AggregateError
+	isTrue(t, errors.As(err, &agrErr))
+	var paths, keys []string
+	for _, err := range agrErr.Errors {
+		var ferr FieldError
+		isTrue(t, errors.As(err, &ferr))
+		paths = append(paths, ferr.FieldPath())
+		keys = append(keys, ferr.EnvKey())
+	}
+	isEqual(t, []string{
+		"Port", "Ports", "Labels", "Addr", "Count", "Token", "Ch", "Chans", "Funcs", "Host",
+		"Database.Port", "Workers[0].Delay",
+	}, paths)
+	isEqual(t, []string{
+		"PORT", "PORTS", "LABELS", "ADDR", "COUNT", "TOKEN", "CH", "CHANS", "FUNCS", "HOST",
+		"DB_PORT", "WORKERS_0_DELAY",
+	}, keys)
+}
+
+func TestCheckPointerCollections(t *testing.T) {
+	type Config struct {
+		Ints   *[]int          `env:"INTS"`
+		Labels *map[string]int `env:"LABELS"`
+		Pair   *[2]int         `env:"PAIR"`
+		Host   *string         `env:"HOST"`
+	}
+
+	message := `env: no parser found for field "Ints" of type "*[]int"; ` +
+		`no parser found for field "Labels" of type "*map[string]int"; ` +
+		`no parser found for field "Pair" of type "*[2]int"`
+	isErrorWithMessage(t, Check[Config](Options{}), message)
+
+	_, err := ParseAsWithOptions[Config](Options{Environment: map[string]string{
+		"INTS":   "1,2",
+		"LABELS": "a:1",
+		"PAIR":   "1,2",
+		"HOST":   "localhost",
+	}})
+	isErrorWithMessage(t, err, message)
+}
+
+func TestCheckSeparators(t *testing.T) {
+	type Config struct {
+		Routes   [][]string                   `env:"ROUTES"`
+		Defaults [][]int                      `env:"DEFAULTS" envDefault:"1,2"`
+		Optional Optional[[][]string]         `env:"OPTIONAL" envSeparator:";"`
+		Labels   map[string]map[string]string `env:"LABELS" envSeparator:";,"`
+		Valid    map[string][]int             `env:"VALID" envSeparator:";,"`
+	}
+
+	err := Check[Config](Options{})
+	isErrorWithMessage(t, err, `env: parse error on field "Routes" of type "[][]string": envSeparator "" should have one separator per level of [][]string; `+
+		`parse error on field "Defaults" of type "[][]int": envSeparator "" should have one separator per level of [][]int; `+
+		`parse error on field "Optional" of type "[][]string": envSeparator ";" should have one separator per level of [][]string`)
+}
+
+func TestCheckFuncMap(t *testing.T) {
+	type Config struct {
+		Ch chan int `env:"CH" envDefault:"1"`
+	}
+
+	err := Check[Config](Options{FuncMap: map[reflect.Type]ParserFunc{
+		reflect.TypeOf(make(chan int)): func(v string) (interface{}, error) {
+			return make(chan int), nil
+		},
+	}})
+	isNoErr(t, err)
+}
+
+func TestCheckIgnoresSources(t *testing.T) {
+	type Config struct {
+		Port int `env:"PORT" envDefault:"80"`
+	}
+
+	file := filepath.Join(t.TempDir(), "file")
+	isNoErr(t, os.WriteFile(file, nil, 0o600))
+
+	err := Check[Config](Options{
+		Environment: map[string]string{"PORT": "http", "PROFILE": "prod"},
+		Dirs:        []DirSource{{Path: file}},
+		ProfileKey:  "PROFILE",
+	})
+	isNoErr(t, err)
+}
+
+func TestCheckVariants(t *testing.T) {
+	type Config struct {
+		Storage storage `env:"STORAGE" envDefault:"azure"`
+	}
+
+	opts := Options{}
+	RegisterVariant[storage, s3Storage](&opts, "s3")
+	RegisterVariant[storage, gcsStorage](&opts, "gcs")
+
+	err := Check[Config](opts)
+	isErrorWithMessage(t, err, `env: environment variable "STORAGE" has unknown variant "azure", expected one of ["gcs" "s3"]`)
+
+	err = Check[Config](Options{})
+	isErrorWithMessage(t, err, `env: no parser found for field "Storage" of type "env.storage"`)
+}
+
+func TestCheckNotStruct(t *testing.T) {
+	err := Check[int](Options{})
+	isErrorWithMessage(t, err, "env: expected a pointer to a Struct")
+}
@@ -0,0 +1,168 @@
+// Command envgen writes example environment files for a configuration
+// struct, e.g. a `.env.example` file:
+//
+//	go run github.com/caarlos0/env/v11/cmd/envgen -type example.com/app/config.Config -o .env.example
+//
+// or Kubernetes manifests:
+//
+//	go run github.com/caarlos0/env/v11/cmd/envgen -type example.com/app/config.Config -format kubernetes -name app
+//
+// It must be run from the module holding the type. It builds and runs a
+// temporary program, in a temporary directory inside the current directory,
+// that calls env.WriteEnvFile or env.Kubernetes with the type.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	gofmt "go/format"
+	"go/token"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// formats maps the values of the -format flag to the env.EnvFileFormat to
+// write, or to the KubernetesManifests fields to write, separated by "---".
+var formats = map[string]struct { //nolint:gochecknoglobals
+	envFile    string
+	kubernetes []string
+}{
+	"dotenv":             {envFile: "DotEnv"},
+	"systemd":            {envFile: "SystemdEnvironmentFile"},
+	"docker":             {envFile: "DockerEnvFile"},
+	"kubernetes":         {kubernetes: []string{"ConfigMap", "Secret"}},
+	"kubernetes-env":     {kubernetes: []string{"Env"}},
+	"kubernetes-envfrom": {kubernetes: []string{"EnvFrom"}},
+}
+
+type config struct {
+	typ    string
+	format string
+	prefix string
+	name   string
+	output string
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, "envgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdout, stderr io.Writer) error {
+	var cfg config
+	fs := flag.NewFlagSet("envgen", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&cfg.typ, "type", "", "the configuration struct, as `import/path.Type`")
+	fs.StringVar(&cfg.format, "format", "dotenv", "the output format: dotenv, systemd, docker, kubernetes, kubernetes-env or kubernetes-envfrom")
+	fs.StringVar(&cfg.prefix, "prefix", "", "the prefix of all the variables, as in env.Options")
+	fs.StringVar(&cfg.name, "name", "", "the name of the Kubernetes ConfigMap and Secret")
+	fs.StringVar(&cfg.output, "o", "", "the output file (default: standard output)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	src, err := generate(cfg)
+	if err != nil {
+		return err
+	}
+
+	dir, err := os.MkdirTemp(".", "envgen")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	if err := os.WriteFile(dir+"/main.go", src, 0o600); err != nil {
+		return err
+	}
+
+	var out bytes.Buffer
+	cmd := exec.Command("go", "run", "./"+dir)
+	cmd.Stdout = &out
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		return err
+	}
+
+	if cfg.output == "" {
+		_, err := stdout.Write(out.Bytes())
+		return err
+	}
+	return os.WriteFile(cfg.output, out.Bytes(), 0o644) //nolint:gosec
+}
+
+var program = template.Must(template.New("main").Parse(`// Code generated by envgen. DO NOT EDIT.
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/caarlos0/env/v11"
+
+	config {{ .Package }}
+)
+
+func main() {
+	opts := env.Options{Prefix: {{ .Prefix }}}
+{{- if .EnvFile }}
+	if err := env.WriteEnvFile(os.Stdout, &config.{{ .Type }}{}, opts, env.{{ .EnvFile }}); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+{{- else }}
+	m, err := env.Kubernetes(&config.{{ .Type }}{}, {{ .Name }}, opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	var sep string
+	for _, b := range [][]byte{ {{- range $i, $f := .Kubernetes }}{{ if $i }}, {{ end }}m.{{ $f }}{{ end -}} } {
+		if b != nil {
+			fmt.Print(sep, string(b))
+			sep = "---\n"
+		}
+	}
+{{- end }}
+}
+`)) //nolint:gochecknoglobals
+
+// generate returns the source of the program writing the file described by
+// cfg.
+func generate(cfg config) ([]byte, error) {
+	i := strings.LastIndexByte(cfg.typ, '.')
+	if i <= 0 || strings.HasSuffix(cfg.typ[:i], "/") || !token.IsIdentifier(cfg.typ[i+1:]) {
+		return nil, errors.New("-type must be an import path followed by a type name, e.g. example.com/app/config.Config")
+	}
+
+	format, ok := formats[cfg.format]
+	if !ok {
+		return nil, fmt.Errorf("unknown format %q", cfg.format)
+	}
+	if format.kubernetes != nil && cfg.name == "" {
+		return nil, fmt.Errorf("-name is required with -format %s", cfg.format)
+	}
+
+	var buf bytes.Buffer
+	err := program.Execute(&buf, map[string]interface{}{
+		"Package":    strconv.Quote(cfg.typ[:i]),
+		"Type":       cfg.typ[i+1:],
+		"Prefix":     strconv.Quote(cfg.prefix),
+		"Name":       strconv.Quote(cfg.name),
+		"EnvFile":    format.envFile,
+		"Kubernetes": format.kubernetes,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return gofmt.Source(buf.Bytes())
+}
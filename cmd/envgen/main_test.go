@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a program")
+	}
+
+	var stdout, stderr bytes.Buffer
+	err := run([]string{"-type", "github.com/caarlos0/env/v11/cmd/envgen/testdata/config.Config", "-prefix", "APP_"}, &stdout, &stderr)
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n%s", err, stderr.String())
+	}
+
+	want := `# Address to listen on.
+# required
+APP_HOST=
+
+APP_PORT=8080
+`
+	if stdout.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", stdout.String(), want)
+	}
+
+	output := filepath.Join(t.TempDir(), "app.env")
+	err = run([]string{"-type", "github.com/caarlos0/env/v11/cmd/envgen/testdata/config.Config", "-format", "docker", "-o", output}, &stdout, &stderr)
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n%s", err, stderr.String())
+	}
+	b, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(string(b), "\nPORT=8080\n") {
+		t.Errorf("unexpected output:\n%s", b)
+	}
+
+	stdout.Reset()
+	err = run([]string{"-type", "github.com/caarlos0/env/v11/cmd/envgen/testdata/config.Config", "-format", "kubernetes-envfrom", "-name", "app"}, &stdout, &stderr)
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n%s", err, stderr.String())
+	}
+	if want := "envFrom:\n  - configMapRef:\n      name: \"app\"\n"; stdout.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", stdout.String(), want)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	src, err := generate(config{typ: "example.com/app/config.Config", format: "systemd", prefix: `APP_"`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`config "example.com/app/config"`,
+		`env.Options{Prefix: "APP_\""}`,
+		`&config.Config{}`,
+		`env.SystemdEnvironmentFile`,
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("expected %q in:\n%s", want, src)
+		}
+	}
+
+	src, err = generate(config{typ: "example.com/app/config.Config", format: "kubernetes", name: "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`env.Kubernetes(&config.Config{}, "app", opts)`,
+		`[][]byte{m.ConfigMap, m.Secret}`,
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("expected %q in:\n%s", want, src)
+		}
+	}
+
+	for _, tc := range []struct {
+		cfg config
+		err string
+	}{
+		{config{typ: "Config", format: "dotenv"}, "-type must be an import path followed by a type name, e.g. example.com/app/config.Config"},
+		{config{typ: "example.com/app/config.", format: "dotenv"}, "-type must be an import path followed by a type name, e.g. example.com/app/config.Config"},
+		{config{typ: "example.com/app/.Config", format: "dotenv"}, "-type must be an import path followed by a type name, e.g. example.com/app/config.Config"},
+		{config{typ: "example.com/app/config.Config", format: "yaml"}, `unknown format "yaml"`},
+		{config{typ: "example.com/app/config.Config", format: "kubernetes"}, "-name is required with -format kubernetes"},
+	} {
+		if _, err := generate(tc.cfg); err == nil || err.Error() != tc.err {
+			t.Errorf("expected error %q, got %v", tc.err, err)
+		}
+	}
+}
@@ -0,0 +1,6 @@
+package config
+
+type Config struct {
+	Host string `env:"HOST,required" envDescription:"Address to listen on."`
+	Port int    `env:"PORT" envDefault:"8080"`
+}
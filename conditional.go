@@ -0,0 +1,131 @@
+package env
+
+import (
+	"sort"
+	"strings"
+)
+
+// conditional holds what is needed to check the conditional requirements of a
+// field once all the other fields were parsed.
+type conditional struct {
+	path         string
+	key          string
+	prefix       string
+	set          bool // set explicitly, not from envDefault
+	hasValue     bool
+	requiredIf   string
+	requiredWith string
+	exclusive    string
+}
+
+func newConditional(path string, fieldParams FieldParams, opts Options, value string) conditional {
+	_, exists := opts.lookupEnv(fieldParams.Key)
+	return conditional{
+		path:         path,
+		key:          fieldParams.Key,
+		prefix:       opts.Prefix,
+		set:          isSetExplicitly(fieldParams, opts),
+		hasValue:     exists || value != "",
+		requiredIf:   fieldParams.RequiredIf,
+		requiredWith: fieldParams.RequiredWith,
+		exclusive:    fieldParams.Exclusive,
+	}
+}
+
+// checkConditionals validates the `envRequiredIf`, `envRequiredWith` and
+// `envExclusive` tags of all the parsed fields.
+func checkConditionals(opts Options) error {
+	var agrErr AggregateError
+	groups := map[string][]conditional{}
+	var groupNames []string
+
+	for _, c := range *opts.conditionals {
+		if !c.hasValue && c.isRequired(opts) {
+			agrErr.Errors = append(agrErr.Errors, VarIsNotSetError{Key: c.key, Path: c.path})
+		}
+		if c.exclusive != "" {
+			if _, ok := groups[c.exclusive]; !ok {
+				groupNames = append(groupNames, c.exclusive)
+			}
+			groups[c.exclusive] = append(groups[c.exclusive], c)
+		}
+	}
+
+	sort.Strings(groupNames)
+	for _, group := range groupNames {
+		var keys []string
+		for _, c := range groups[group] {
+			if c.set {
+				keys = append(keys, c.key)
+			}
+		}
+		if len(keys) > 1 {
+			agrErr.Errors = append(agrErr.Errors, newExclusiveVarsError(group, keys))
+		}
+	}
+
+	if len(agrErr.Errors) == 0 {
+		return nil
+	}
+	return agrErr
+}
+
+func (c conditional) isRequired(opts Options) bool {
+	for _, cond := range splitConditions(c.requiredIf) {
+		key, value, _ := strings.Cut(cond, "=")
+		if c.resolve(strings.TrimSpace(key), opts) == strings.TrimSpace(value) {
+			return true
+		}
+	}
+	for _, key := range splitConditions(c.requiredWith) {
+		if c.resolve(key, opts) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve returns the value of key, looking it up relative to the prefix of the
+// field first.
+func (c conditional) resolve(key string, opts Options) string {
+	if c.prefix != "" {
+		if value, ok := resolvedValue(c.prefix+key, opts); ok {
+			return value
+		}
+	}
+	value, _ := resolvedValue(key, opts)
+	return value
+}
+
+// resolvedValue returns the value of a field that was already parsed, or the
+// value of the variable if no field uses it.
+func resolvedValue(key string, opts Options) (string, bool) {
+	if value, ok := opts.rawEnvVars[key]; ok {
+		return value, true
+	}
+	return opts.lookupEnv(key)
+}
+
+func splitConditions(s string) []string {
+	if s == "" {
+		return nil
+	}
+	var result []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			result = append(result, part)
+		}
+	}
+	return result
+}
+
+func isSetExplicitly(fieldParams FieldParams, opts Options) bool {
+	if value, _ := opts.lookupEnv(fieldParams.Key); value != "" {
+		return true
+	}
+	if !fieldParams.KeyFile {
+		return false
+	}
+	value, _ := opts.lookupEnv(fieldParams.Key + opts.KeyFileSuffix)
+	return value != ""
+}
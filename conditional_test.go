@@ -0,0 +1,137 @@
+package env
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRequiredIf(t *testing.T) {
+	type Config struct {
+		Storage string `env:"STORAGE" envDefault:"local"`
+		S3      struct {
+			Bucket string `env:"BUCKET" envRequiredIf:"STORAGE=s3"`
+			Region string `env:"REGION" envRequiredIf:"STORAGE=s3" envDefault:"us-east-1"`
+		} `envPrefix:"S3_"`
+		Dir string `env:"DIR" envRequiredIf:"STORAGE=local, STORAGE=nfs"`
+	}
+
+	for name, tt := range map[string]struct {
+		env  map[string]string
+		errs []error
+	}{
+		"local": {
+			env:  map[string]string{"DIR": "/tmp"},
+			errs: nil,
+		},
+		"local missing dir": {
+			env:  map[string]string{},
+			errs: []error{VarIsNotSetError{Key: "DIR", Path: "Dir"}},
+		},
+		"nfs missing dir": {
+			env:  map[string]string{"STORAGE": "nfs"},
+			errs: []error{VarIsNotSetError{Key: "DIR", Path: "Dir"}},
+		},
+		"s3": {
+			env:  map[string]string{"STORAGE": "s3", "S3_BUCKET": "foo"},
+			errs: nil,
+		},
+		"s3 missing bucket": {
+			env:  map[string]string{"STORAGE": "s3"},
+			errs: []error{VarIsNotSetError{Key: "S3_BUCKET", Path: "S3.Bucket"}},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := ParseWithOptions(&Config{}, Options{Environment: tt.env})
+			if tt.errs == nil {
+				isNoErr(t, err)
+				return
+			}
+			isEqual(t, AggregateError{Errors: tt.errs}, err)
+		})
+	}
+}
+
+func TestRequiredIfPrefix(t *testing.T) {
+	type Backend struct {
+		Kind string `env:"KIND"`
+		URL  string `env:"URL" envRequiredIf:"KIND=remote,MODE=production"`
+	}
+	type Config struct {
+		Mode    string  `env:"MODE"`
+		Primary Backend `envPrefix:"PRIMARY_"`
+	}
+
+	err := ParseWithOptions(&Config{}, Options{Environment: map[string]string{
+		"PRIMARY_KIND": "remote",
+	}})
+	isEqual(t, AggregateError{Errors: []error{
+		VarIsNotSetError{Key: "PRIMARY_URL", Path: "Primary.URL"},
+	}}, err)
+
+	err = ParseWithOptions(&Config{}, Options{Environment: map[string]string{
+		"MODE": "production",
+	}})
+	isEqual(t, AggregateError{Errors: []error{
+		VarIsNotSetError{Key: "PRIMARY_URL", Path: "Primary.URL"},
+	}}, err)
+
+	isNoErr(t, ParseWithOptions(&Config{}, Options{Environment: map[string]string{
+		"PRIMARY_KIND": "local",
+	}}))
+}
+
+func TestRequiredWith(t *testing.T) {
+	type Config struct {
+		Cert string `env:"TLS_CERT" envRequiredWith:"TLS_KEY"`
+		Key  string `env:"TLS_KEY" envRequiredWith:"TLS_CERT"`
+	}
+
+	isNoErr(t, ParseWithOptions(&Config{}, Options{Environment: map[string]string{}}))
+	isNoErr(t, ParseWithOptions(&Config{}, Options{Environment: map[string]string{
+		"TLS_CERT": "cert.pem",
+		"TLS_KEY":  "key.pem",
+	}}))
+
+	err := ParseWithOptions(&Config{}, Options{Environment: map[string]string{
+		"TLS_KEY": "key.pem",
+	}})
+	isErrorWithMessage(t, err, `env: required environment variable "TLS_CERT" is not set`)
+}
+
+func TestConditionalWithOtherErrors(t *testing.T) {
+	type Config struct {
+		Cert string `env:"TLS_CERT"`
+		Key  string `env:"TLS_KEY" envRequiredWith:"TLS_CERT"`
+		Port int    `env:"PORT,nope"`
+	}
+
+	err := ParseWithOptions(&Config{}, Options{Environment: map[string]string{
+		"TLS_CERT": "cert.pem",
+	}})
+	isErrorWithMessage(t, err, `env: tag option "nope" not supported; required environment variable "TLS_KEY" is not set`)
+}
+
+func TestExclusive(t *testing.T) {
+	type Config struct {
+		Password     string `env:"PASSWORD" envExclusive:"auth"`
+		PasswordFile string `env:"PASSWORD_PATH" envExclusive:"auth"`
+		Token        string `env:"TOKEN" envExclusive:"auth"`
+		Host         string `env:"HOST" envExclusive:"addr" envDefault:"localhost"`
+		Socket       string `env:"SOCKET" envExclusive:"addr"`
+	}
+
+	isNoErr(t, ParseWithOptions(&Config{}, Options{Environment: map[string]string{
+		"TOKEN":  "foo",
+		"SOCKET": "/var/run/foo.sock",
+	}}))
+
+	err := ParseWithOptions(&Config{}, Options{Environment: map[string]string{
+		"PASSWORD": "foo",
+		"TOKEN":    "bar",
+		"HOST":     "example.com",
+		"SOCKET":   "/var/run/foo.sock",
+	}})
+	isErrorWithMessage(t, err, `env: environment variables ["HOST" "SOCKET"] are mutually exclusive (group "addr"), but more than one is set; `+
+		`environment variables ["PASSWORD" "TOKEN"] are mutually exclusive (group "auth"), but more than one is set`)
+	isTrue(t, errors.Is(err, ExclusiveVarsError{}))
+}
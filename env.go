@@ -17,10 +17,17 @@ package env
 
 import (
 	"encoding"
+	"encoding/json"
 	"fmt"
+	"io"
+	"io/fs"
 	"net/url"
 	"os"
+	"path"
+	"path/filepath"
 	"reflect"
+	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -121,6 +128,10 @@ type ParserFunc func(v string) (interface{}, error)
 // OnSetFn is a hook that can be run when a value is set.
 type OnSetFn func(tag string, value interface{}, isDefault bool)
 
+// OnDuplicateKeyFn is a hook that can be run when a field resolves to the same
+// key as a previous one.
+type OnDuplicateKeyFn func(err DuplicateKeyError)
+
 // processFieldFn is a function which takes all information about a field and processes it.
 type processFieldFn func(
 	refField reflect.Value,
@@ -159,6 +170,18 @@ type Options struct {
 	// variable names conventions.
 	UseFieldNameByDefault bool
 
+	// UseFieldNameAsPrefix defines whether or not nested structs without an
+	// `envPrefix` tag should get a prefix derived from their field name, e.g.
+	// `Database DBConfig` gets "DATABASE_". It only has effect along with
+	// UseFieldNameByDefault, and is not applied to embedded structs.
+	UseFieldNameAsPrefix bool
+
+	// PrefixSeparator is appended to the prefixes derived from field names
+	// when UseFieldNameAsPrefix is enabled, and to the indexes of the items of
+	// slices of structs with such a prefix, e.g. "SHARDS__0__HOST" with "__".
+	// Defaults to "_".
+	PrefixSeparator string
+
 	// SetDefaultsForZeroValuesOnly defines whether to set defaults for zero values
 	// If the `env` variable for the value is not set
 	// and `envDefault` is set
@@ -171,27 +194,219 @@ type Options struct {
 	// Custom parse functions for different types.
 	FuncMap map[reflect.Type]ParserFunc
 
+	// UseKeyFile makes every field read its value from the file named by the
+	// KEY_FILE variable when KEY is not set, like the official Docker images do.
+	// It can also be enabled for a single field with the `keyFile` tag option.
+	UseKeyFile bool
+
+	// KeyFileSuffix is the suffix used to find the variable holding the path of
+	// the file when `UseKeyFile` or `keyFile` are used. Defaults to "_FILE".
+	KeyFileSuffix string
+
+	// FileMaxSize is the maximum size, in bytes, of files loaded with the
+	// `file` and `keyFile` tag options, or from Dirs. Zero means no limit.
+	FileMaxSize int64
+
+	// FileTrimTrailingNewline removes a single trailing "\n" or "\r\n" from
+	// the contents of files loaded with the `file` and `keyFile` tag options.
+	FileTrimTrailingNewline bool
+
+	// FileTrimTrailingSpace removes all trailing whitespace from the contents
+	// of files loaded with the `file` and `keyFile` tag options.
+	FileTrimTrailingSpace bool
+
+	// FileStrictPermissions refuses to load files of fields with the
+	// `sensitive` tag option if they are readable by group or others.
+	// It has no effect on Windows.
+	FileStrictPermissions bool
+
+	// FileBaseDir restricts the files loaded with the `file` and `keyFile`
+	// tag options to the ones inside this directory, after resolving symlinks.
+	FileBaseDir string
+
+	// FileSystem is used to load files with the `file` and `keyFile` tag
+	// options instead of the OS file system.
+	FileSystem fs.FS
+
+	// Profile is the active profile, used to pick the defaults declared with
+	// `envDefault.<profile>` tags over the ones declared with `envDefault`.
+	Profile string
+
+	// ProfileKey is the name of a variable holding the active profile, e.g.
+	// "APP_ENV". It is only used if Profile is empty.
+	ProfileKey string
+
+	// KeyMatcher is used to match keys with the ones in the environment when
+	// they are not found as-is, e.g. RelaxedKeyMatcher.
+	KeyMatcher KeyMatcher
+
+	// NameMapper converts field names to keys when UseFieldNameByDefault is
+	// enabled. Defaults to converting "FieldName" into "FIELD_NAME".
+	NameMapper NameMapper
+
+	// Variants maps interface types to the concrete types that can be set in
+	// fields of that type, indexed by the value of the field's variable.
+	// Use RegisterVariant to add to it.
+	Variants map[reflect.Type]map[string]reflect.Type
+
+	// Dirs are directories in which each file is a variable, named after the
+	// file. Variables set in Environment take precedence over them.
+	Dirs []DirSource
+
+	// OnDuplicateKey is called when a field resolves to the same key as a
+	// previous one.
+	OnDuplicateKey OnDuplicateKeyFn
+
+	// ErrorOnDuplicateKeys makes parsing fail with a DuplicateKeyError when a
+	// field resolves to the same key as a previous one.
+	ErrorOnDuplicateKeys bool
+
+	// IntegerLiterals parses integers as Go integer literals, accepting the
+	// 0x, 0o, 0b and 0 prefixes and _ separators, e.g. "0x1F" or "1_000_000",
+	// using the full width of int and uint, and parsing os.FileMode values as
+	// octal, e.g. "644". It can also be enabled for a single field, with a
+	// given base, with the `envBase` tag.
+	IntegerLiterals bool
+
+	// LenientBools parses booleans with BoolValues instead of
+	// strconv.ParseBool, accepting values such as "yes", "no", "on" or "off".
+	// It can also be enabled for a single field with the `lenientBool` tag
+	// option.
+	LenientBools bool
+
+	// BoolValues are the values accepted by lenient booleans, matched
+	// regardless of their case, so values only differing in case must mean
+	// the same boolean. Defaults to DefaultBoolValues().
+	BoolValues map[string]bool
+
 	// Used internally. maps the env variable key to its resolved string value.
 	// (for env var expansion)
 	rawEnvVars map[string]string
+
+	// Used internally. keys of the environment indexed by their KeyMatcher
+	// form.
+	matchedEnv map[string]string
+
+	// Used internally. files of Dirs, read when their key is looked up.
+	dirFiles dirFiles
+
+	// Used internally. path of the struct being parsed, e.g. "Server.TLS".
+	fieldPath string
+
+	// Used internally. fields with conditional requirements, checked after
+	// all fields are parsed.
+	conditionals *[]conditional
+
+	// Used internally. paths of the fields indexed by the KeyMatcher form of
+	// their keys.
+	keys map[string]string
 }
 
 func (opts *Options) getRawEnv(s string) string {
 	val := opts.rawEnvVars[s]
 	if val == "" {
-		val = opts.Environment[s]
+		val, _ = opts.lookupEnv(s)
 	}
 	return os.Expand(val, opts.getRawEnv)
 }
 
+// lookupEnv returns the value of key in the environment, using the KeyMatcher
+// if any.
+func (opts *Options) lookupEnv(key string) (string, bool) {
+	key = opts.envKey(key)
+	if f, ok := opts.dirFiles[key]; ok {
+		value, ok, _ := f.load()
+		return value, ok
+	}
+	value, ok := opts.Environment[key]
+	return value, ok
+}
+
+// dirFileError returns the error reading the file of Dirs holding the value
+// of key, if any.
+func (opts *Options) dirFileError(key string) error {
+	if f, ok := opts.dirFiles[opts.envKey(key)]; ok {
+		_, _, err := f.load()
+		return err
+	}
+	return nil
+}
+
+// envKey returns the key of the environment matching key, using the
+// KeyMatcher if any, or key if there is none.
+func (opts *Options) envKey(key string) string {
+	if _, ok := opts.Environment[key]; ok || opts.KeyMatcher == nil {
+		return key
+	}
+	if envKey, ok := opts.matchedEnv[opts.KeyMatcher(key)]; ok {
+		return envKey
+	}
+	return key
+}
+
+// checkDuplicateKey records the key of a field, reporting a DuplicateKeyError
+// if another field already has it.
+func (opts *Options) checkDuplicateKey(params FieldParams, path string) error {
+	if params.OwnKey == "" {
+		return nil
+	}
+
+	matchKey := opts.matchKey(params.Key)
+	first, ok := opts.keys[matchKey]
+	if !ok {
+		opts.keys[matchKey] = path
+		return nil
+	}
+
+	err := newDuplicateKeyError(params.Key, first, path)
+	if opts.OnDuplicateKey != nil {
+		opts.OnDuplicateKey(err.(DuplicateKeyError))
+	}
+	if opts.ErrorOnDuplicateKeys {
+		return err
+	}
+	return nil
+}
+
+// matchKey returns the form in which key is compared with others.
+func (opts *Options) matchKey(key string) string {
+	if opts.KeyMatcher == nil {
+		return key
+	}
+	return opts.KeyMatcher(key)
+}
+
+// KeyMatcher returns the form in which a key is compared with the keys in the
+// environment: two keys match if the function returns the same value for both.
+type KeyMatcher func(key string) string
+
+// RelaxedKeyMatcher is a KeyMatcher that ignores case, and treats '-', '.' and
+// '_' as equivalent, so "APP_DB_HOST" matches both "app.db.host" and
+// "app-db-host".
+func RelaxedKeyMatcher(key string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == '.' {
+			return underscore
+		}
+		return unicode.ToUpper(r)
+	}, key)
+}
+
+// NameMapper converts a struct field name into an environment variable key.
+type NameMapper func(fieldName string) string
+
 func defaultOptions() Options {
 	return Options{
 		TagName:             "env",
 		PrefixTagName:       "envPrefix",
 		DefaultValueTagName: "envDefault",
 		Environment:         toMap(os.Environ()),
+		KeyFileSuffix:       "_FILE",
+		PrefixSeparator:     string(underscore),
 		FuncMap:             defaultTypeParsers(),
 		rawEnvVars:          make(map[string]string),
+		conditionals:        &[]conditional{},
+		keys:                make(map[string]string),
 	}
 }
 
@@ -223,7 +438,7 @@ func mergeOptions[T any](target, source *T) {
 
 func isZero(v reflect.Value) bool {
 	switch v.Kind() {
-	case reflect.Func, reflect.Map, reflect.Slice:
+	case reflect.Func, reflect.Map, reflect.Slice, reflect.Interface:
 		return v.IsNil()
 	default:
 		zero := reflect.Zero(v.Type())
@@ -231,13 +446,59 @@ func isZero(v reflect.Value) bool {
 	}
 }
 
-func customOptions(opts Options) Options {
+func customOptions(opts Options) (Options, error) {
 	defOpts := defaultOptions()
 	mergeOptions(&defOpts, &opts)
-	return defOpts
+
+	if defOpts.BoolValues != nil {
+		values, err := normalizeBoolValues(defOpts.BoolValues)
+		if err != nil {
+			return Options{}, newAggregateError(err)
+		}
+		defOpts.BoolValues = values
+	}
+
+	env, files, err := withDirs(defOpts.Environment, defOpts.Dirs, defOpts.FileMaxSize)
+	if err != nil {
+		return Options{}, newAggregateError(err)
+	}
+	defOpts.Environment = env
+	defOpts.dirFiles = files
+
+	if defOpts.KeyMatcher != nil {
+		defOpts.matchedEnv = matchEnv(defOpts.Environment, defOpts.KeyMatcher)
+	}
+
+	if defOpts.Profile == "" && defOpts.ProfileKey != "" {
+		if err := defOpts.dirFileError(defOpts.ProfileKey); err != nil {
+			return Options{}, newAggregateError(err)
+		}
+		defOpts.Profile, _ = defOpts.lookupEnv(defOpts.ProfileKey)
+	}
+
+	return defOpts, nil
 }
 
-func optionsWithSliceEnvPrefix(opts Options, index int) Options {
+// matchEnv indexes the keys of env by their matcher form. If more than one key
+// has the same form, the first one in lexical order is used.
+func matchEnv(env map[string]string, matcher KeyMatcher) map[string]string {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	result := make(map[string]string, len(env))
+	for _, k := range keys {
+		mk := matcher(k)
+		if _, ok := result[mk]; !ok {
+			result[mk] = k
+		}
+	}
+	return result
+}
+
+func optionsWithSliceEnvPrefix(opts Options, index int, separator string) Options {
 	return Options{
 		Environment:                  opts.Environment,
 		TagName:                      opts.TagName,
@@ -245,11 +506,37 @@ func optionsWithSliceEnvPrefix(opts Options, index int) Options {
 		DefaultValueTagName:          opts.DefaultValueTagName,
 		RequiredIfNoDef:              opts.RequiredIfNoDef,
 		OnSet:                        opts.OnSet,
-		Prefix:                       fmt.Sprintf("%s%d_", opts.Prefix, index),
+		Prefix:                       fmt.Sprintf("%s%d%s", opts.Prefix, index, separator),
 		UseFieldNameByDefault:        opts.UseFieldNameByDefault,
+		UseFieldNameAsPrefix:         opts.UseFieldNameAsPrefix,
+		PrefixSeparator:              opts.PrefixSeparator,
 		SetDefaultsForZeroValuesOnly: opts.SetDefaultsForZeroValuesOnly,
 		FuncMap:                      opts.FuncMap,
+		UseKeyFile:                   opts.UseKeyFile,
+		KeyFileSuffix:                opts.KeyFileSuffix,
+		FileMaxSize:                  opts.FileMaxSize,
+		FileTrimTrailingNewline:      opts.FileTrimTrailingNewline,
+		FileTrimTrailingSpace:        opts.FileTrimTrailingSpace,
+		FileStrictPermissions:        opts.FileStrictPermissions,
+		FileBaseDir:                  opts.FileBaseDir,
+		FileSystem:                   opts.FileSystem,
+		Profile:                      opts.Profile,
+		ProfileKey:                   opts.ProfileKey,
+		KeyMatcher:                   opts.KeyMatcher,
+		NameMapper:                   opts.NameMapper,
+		Variants:                     opts.Variants,
+		Dirs:                         opts.Dirs,
+		OnDuplicateKey:               opts.OnDuplicateKey,
+		ErrorOnDuplicateKeys:         opts.ErrorOnDuplicateKeys,
+		IntegerLiterals:              opts.IntegerLiterals,
+		LenientBools:                 opts.LenientBools,
+		BoolValues:                   opts.BoolValues,
 		rawEnvVars:                   opts.rawEnvVars,
+		matchedEnv:                   opts.matchedEnv,
+		dirFiles:                     opts.dirFiles,
+		fieldPath:                    fmt.Sprintf("%s[%d]", opts.fieldPath, index),
+		conditionals:                 opts.conditionals,
+		keys:                         opts.keys,
 	}
 }
 
@@ -261,12 +548,75 @@ func optionsWithEnvPrefix(field reflect.StructField, opts Options) Options {
 		DefaultValueTagName:          opts.DefaultValueTagName,
 		RequiredIfNoDef:              opts.RequiredIfNoDef,
 		OnSet:                        opts.OnSet,
-		Prefix:                       opts.Prefix + field.Tag.Get(opts.PrefixTagName),
+		Prefix:                       opts.Prefix + fieldPrefix(field, opts),
 		UseFieldNameByDefault:        opts.UseFieldNameByDefault,
+		UseFieldNameAsPrefix:         opts.UseFieldNameAsPrefix,
+		PrefixSeparator:              opts.PrefixSeparator,
 		SetDefaultsForZeroValuesOnly: opts.SetDefaultsForZeroValuesOnly,
 		FuncMap:                      opts.FuncMap,
+		UseKeyFile:                   opts.UseKeyFile,
+		KeyFileSuffix:                opts.KeyFileSuffix,
+		FileMaxSize:                  opts.FileMaxSize,
+		FileTrimTrailingNewline:      opts.FileTrimTrailingNewline,
+		FileTrimTrailingSpace:        opts.FileTrimTrailingSpace,
+		FileStrictPermissions:        opts.FileStrictPermissions,
+		FileBaseDir:                  opts.FileBaseDir,
+		FileSystem:                   opts.FileSystem,
+		Profile:                      opts.Profile,
+		ProfileKey:                   opts.ProfileKey,
+		KeyMatcher:                   opts.KeyMatcher,
+		NameMapper:                   opts.NameMapper,
+		Variants:                     opts.Variants,
+		Dirs:                         opts.Dirs,
+		OnDuplicateKey:               opts.OnDuplicateKey,
+		ErrorOnDuplicateKeys:         opts.ErrorOnDuplicateKeys,
+		IntegerLiterals:              opts.IntegerLiterals,
+		LenientBools:                 opts.LenientBools,
+		BoolValues:                   opts.BoolValues,
 		rawEnvVars:                   opts.rawEnvVars,
+		matchedEnv:                   opts.matchedEnv,
+		dirFiles:                     opts.dirFiles,
+		fieldPath:                    fieldPath(field, opts),
+		conditionals:                 opts.conditionals,
+		keys:                         opts.keys,
+	}
+}
+
+// fieldPrefix returns the prefix the field adds to the keys of its children.
+func fieldPrefix(field reflect.StructField, opts Options) string {
+	if prefix, ok := field.Tag.Lookup(opts.PrefixTagName); ok {
+		return prefix
+	}
+	if opts.UseFieldNameByDefault && opts.UseFieldNameAsPrefix && !field.Anonymous {
+		return fieldNameToKey(field.Name, opts) + opts.PrefixSeparator
 	}
+	return ""
+}
+
+// indexSeparator returns the separator following the index of the items of a
+// slice of structs field: PrefixSeparator if its prefix is derived from its
+// name, and "_" otherwise.
+func indexSeparator(field reflect.StructField, opts Options) string {
+	if _, ok := field.Tag.Lookup(opts.PrefixTagName); !ok && fieldPrefix(field, opts) != "" {
+		return opts.PrefixSeparator
+	}
+	return string(underscore)
+}
+
+// fieldNameToKey converts a field name into a key.
+func fieldNameToKey(name string, opts Options) string {
+	if opts.NameMapper != nil {
+		return opts.NameMapper(name)
+	}
+	return toEnvName(name)
+}
+
+// fieldPath returns the path of the field in the struct being parsed.
+func fieldPath(field reflect.StructField, opts Options) string {
+	if opts.fieldPath == "" {
+		return field.Name
+	}
+	return opts.fieldPath + "." + field.Name
 }
 
 // Parse parses a struct containing `env` tags and loads its values from
@@ -278,7 +628,11 @@ func Parse(v interface{}) error {
 // ParseWithOptions parses a struct containing `env` tags and loads its values from
 // environment variables.
 func ParseWithOptions(v interface{}, opts Options) error {
-	return parseInternal(v, setField, customOptions(opts))
+	opts, err := customOptions(opts)
+	if err != nil {
+		return err
+	}
+	return parseInternal(v, setField, opts)
 }
 
 // ParseAs parses the given struct type containing `env` tags and loads its
@@ -312,18 +666,75 @@ func GetFieldParams(v interface{}) ([]FieldParams, error) {
 }
 
 // GetFieldParamsWithOptions parses a struct containing `env` tags and returns information about
-// tags it found.
+// tags it found. The files of opts.Dirs are not read.
 func GetFieldParamsWithOptions(v interface{}, opts Options) ([]FieldParams, error) {
+	fields, err := GetFieldsWithOptions(v, opts)
+	if err != nil {
+		return nil, err
+	}
+
 	var result []FieldParams
-	err := parseInternal(
+	for _, field := range fields {
+		result = append(result, field.FieldParams)
+	}
+	return result, nil
+}
+
+// Field contains information about a field and its parsed tags.
+type Field struct {
+	FieldParams
+
+	// Path is the path of the field in the struct, e.g. "Server.TLS.CertFile".
+	Path string
+
+	// Type is the type of the field.
+	Type reflect.Type
+
+	// Tag is the tag of the field.
+	Tag reflect.StructTag
+}
+
+// MarshalJSON encodes f like FieldParams.MarshalJSON, along with its path,
+// type and tag.
+func (f Field) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		jsonFieldParams
+		Path string
+		Type reflect.Type
+		Tag  reflect.StructTag
+	}{f.FieldParams.toJSON(), f.Path, f.Type, f.Tag})
+}
+
+// GetFields parses a struct containing `env` tags and returns information about
+// the fields and tags it found.
+func GetFields(v interface{}) ([]Field, error) {
+	return GetFieldsWithOptions(v, defaultOptions())
+}
+
+// GetFieldsWithOptions parses a struct containing `env` tags and returns information about
+// the fields and tags it found. The files of opts.Dirs are not read.
+func GetFieldsWithOptions(v interface{}, opts Options) ([]Field, error) {
+	opts.Dirs = nil
+	opts, err := customOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Field
+	err = parseInternal(
 		v,
-		func(_ reflect.Value, _ reflect.StructField, _ Options, fieldParams FieldParams) error {
+		func(_ reflect.Value, refTypeField reflect.StructField, opts Options, fieldParams FieldParams) error {
 			if fieldParams.OwnKey != "" {
-				result = append(result, fieldParams)
+				result = append(result, Field{
+					FieldParams: fieldParams,
+					Path:        fieldPath(refTypeField, opts),
+					Type:        refTypeField.Type,
+					Tag:         refTypeField.Tag,
+				})
 			}
 			return nil
 		},
-		customOptions(opts),
+		opts,
 	)
 	if err != nil {
 		return nil, err
@@ -342,7 +753,16 @@ func parseInternal(v interface{}, processField processFieldFn, opts Options) err
 		return newAggregateError(NotStructPtrError{})
 	}
 
-	return doParse(ref, processField, opts)
+	err := doParse(ref, processField, opts)
+	if cerr := checkConditionals(opts); cerr != nil {
+		if err == nil {
+			return cerr
+		}
+		agrErr := err.(AggregateError)
+		agrErr.Errors = append(agrErr.Errors, cerr.(AggregateError).Errors...)
+		return agrErr
+	}
+	return err
 }
 
 func doParse(ref reflect.Value, processField processFieldFn, opts Options) error {
@@ -380,10 +800,10 @@ func doParseField(
 		return nil
 	}
 	if refField.Kind() == reflect.Ptr && refField.Elem().Kind() == reflect.Struct && !refField.IsNil() {
-		return parseInternal(refField.Interface(), processField, optionsWithEnvPrefix(refTypeField, opts))
+		return doParse(refField.Elem(), processField, optionsWithEnvPrefix(refTypeField, opts))
 	}
 	if refField.Kind() == reflect.Struct && refField.CanAddr() && refField.Type().Name() == "" {
-		return parseInternal(refField.Addr().Interface(), processField, optionsWithEnvPrefix(refTypeField, opts))
+		return doParse(refField, processField, optionsWithEnvPrefix(refTypeField, opts))
 	}
 
 	params, err := parseFieldParams(refTypeField, opts)
@@ -395,6 +815,10 @@ func doParseField(
 		return nil
 	}
 
+	if err := opts.checkDuplicateKey(params, fieldPath(refTypeField, opts)); err != nil {
+		return err
+	}
+
 	if err := processField(refField, refTypeField, opts, params); err != nil {
 		return err
 	}
@@ -404,17 +828,65 @@ func doParseField(
 		refField = refField.Elem()
 	}
 
+	if params.InitIfSet && isInvalidPtr(refField) && refField.Type().Elem().Kind() == reflect.Struct &&
+		hasVarsFor(refField.Type().Elem(), refTypeField, opts) {
+		refField.Set(reflect.New(refField.Type().Elem()))
+		refField = refField.Elem()
+	}
+
 	if refField.Kind() == reflect.Struct {
 		return doParse(refField, processField, optionsWithEnvPrefix(refTypeField, opts))
 	}
 
 	if isSliceOfStructs(refTypeField) {
-		return doParseSlice(refField, processField, optionsWithEnvPrefix(refTypeField, opts))
+		return doParseSlice(refField, processField, optionsWithEnvPrefix(refTypeField, opts), indexSeparator(refTypeField, opts))
 	}
 
 	return nil
 }
 
+// hasVarsFor reports whether any variable used by a struct of type typ set in
+// the given field is in the environment. If the field adds a prefix, any
+// variable with that prefix counts.
+func hasVarsFor(typ reflect.Type, field reflect.StructField, opts Options) bool {
+	fieldOpts := optionsWithEnvPrefix(field, opts)
+
+	if fieldPrefix(field, opts) != "" {
+		prefix := opts.matchKey(fieldOpts.Prefix)
+		for key := range opts.Environment {
+			if strings.HasPrefix(opts.matchKey(key), prefix) {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Look for the variables without recording their keys, as they are
+	// recorded when the struct is actually parsed.
+	fieldOpts.keys = make(map[string]string)
+	fieldOpts.OnDuplicateKey = nil
+	fieldOpts.ErrorOnDuplicateKeys = false
+
+	found := false
+	_ = doParse(
+		reflect.New(typ).Elem(),
+		func(_ reflect.Value, _ reflect.StructField, opts Options, fieldParams FieldParams) error {
+			if fieldParams.OwnKey == "" {
+				return nil
+			}
+			if _, ok := opts.lookupEnv(fieldParams.Key); ok {
+				found = true
+			}
+			if _, ok := opts.lookupEnv(fieldParams.Key + opts.KeyFileSuffix); ok && fieldParams.KeyFile {
+				found = true
+			}
+			return nil
+		},
+		fieldOpts,
+	)
+	return found
+}
+
 func isSliceOfStructs(refTypeField reflect.StructField) bool {
 	field := refTypeField.Type
 
@@ -434,14 +906,17 @@ func isSliceOfStructs(refTypeField reflect.StructField) bool {
 	return false
 }
 
-func doParseSlice(ref reflect.Value, processField processFieldFn, opts Options) error {
+// doParseSlice parses the items of a slice of structs, whose variables are
+// prefixed with their index followed by separator.
+func doParseSlice(ref reflect.Value, processField processFieldFn, opts Options, separator string) error {
 	if opts.Prefix != "" && !strings.HasSuffix(opts.Prefix, string(underscore)) {
 		opts.Prefix += string(underscore)
 	}
 
 	var environments []string
+	matchPrefix := opts.matchKey(opts.Prefix)
 	for environment := range opts.Environment {
-		if strings.HasPrefix(environment, opts.Prefix) {
+		if environment = opts.matchKey(environment); strings.HasPrefix(environment, matchPrefix) {
 			environments = append(environments, environment)
 		}
 	}
@@ -450,7 +925,7 @@ func doParseSlice(ref reflect.Value, processField processFieldFn, opts Options)
 		counter := 0
 		for finished := false; !finished; {
 			finished = true
-			prefix := fmt.Sprintf("%s%d%c", opts.Prefix, counter, underscore)
+			prefix := opts.matchKey(fmt.Sprintf("%s%d%s", opts.Prefix, counter, separator))
 			for _, variable := range environments {
 				if strings.HasPrefix(variable, prefix) {
 					counter++
@@ -480,7 +955,7 @@ func doParseSlice(ref reflect.Value, processField processFieldFn, opts Options)
 			if i < initialized {
 				item.Set(ref.Index(i))
 			}
-			if err := doParse(item, processField, optionsWithSliceEnvPrefix(opts, i)); err != nil {
+			if err := doParse(item, processField, optionsWithSliceEnvPrefix(opts, i, separator)); err != nil {
 				return err
 			}
 		}
@@ -499,13 +974,27 @@ func doParseSlice(ref reflect.Value, processField processFieldFn, opts Options)
 }
 
 func setField(refField reflect.Value, refTypeField reflect.StructField, opts Options, fieldParams FieldParams) error {
+	path := fieldPath(refTypeField, opts)
+
 	value, err := get(fieldParams, opts)
 	if err != nil {
-		return err
+		return withField(err, path, fieldParams.Key, "")
+	}
+
+	if fieldParams.RequiredIf != "" || fieldParams.RequiredWith != "" || fieldParams.Exclusive != "" {
+		*opts.conditionals = append(*opts.conditionals, newConditional(path, fieldParams, opts, value))
 	}
 
 	if value != "" && (!opts.SetDefaultsForZeroValuesOnly || refField.IsZero()) {
-		return set(refField, refTypeField, value, opts.FuncMap)
+		if variants, ok := opts.Variants[refField.Type()]; ok && refField.Kind() == reflect.Interface {
+			return setVariant(refField, refTypeField, fieldParams.Key, value, variants, opts)
+		}
+		if err := set(refField, refTypeField, value, opts.FuncMap, fieldParams); err != nil {
+			if fieldParams.Sensitive || fieldParams.LoadFile || fieldParams.KeyFile {
+				err, value = redact(err, value), ""
+			}
+			return withField(err, path, fieldParams.Key, value)
+		}
 	}
 
 	return nil
@@ -540,36 +1029,142 @@ type FieldParams struct {
 	HasDefaultValue bool
 	Required        bool
 	LoadFile        bool
+	KeyFile         bool
+	Sensitive       bool
+	RequiredIf      string
+	RequiredWith    string
+	Exclusive       string
+	Description     string
+	Base            int
+	HasBase         bool
+	LenientBool     bool
 	Unset           bool
 	NotEmpty        bool
 	Expand          bool
+	Quoted          bool
+	TrimSpace       bool
+	SkipEmpty       bool
 	Init            bool
+	InitIfSet       bool
 	Ignored         bool
+
+	// profileDefaults holds the defaults of each profile, encoded with
+	// encodeStringMap so FieldParams stays comparable.
+	profileDefaults string
+
+	// enum holds the `envEnum` tag.
+	enum string
+
+	// boolValues holds the values accepted by lenient booleans, encoded with
+	// encodeBoolValues so FieldParams stays comparable.
+	boolValues string
+}
+
+// ProfileDefaults returns the default values of the field for each profile,
+// declared with `envDefault.<profile>` tags, or nil if there are none.
+func (p FieldParams) ProfileDefaults() map[string]string {
+	return decodeStringMap(p.profileDefaults)
+}
+
+// Enum returns the values listed in the `envEnum` tag of the field, or nil if
+// there are none. They are only descriptive: values outside of them are not
+// rejected.
+func (p FieldParams) Enum() []string {
+	if p.enum == "" {
+		return nil
+	}
+	return strings.Split(p.enum, ",")
+}
+
+// BoolValues returns the values accepted by the field if it is a lenient
+// boolean, in lower case, or nil otherwise.
+func (p FieldParams) BoolValues() map[string]bool {
+	if !p.LenientBool {
+		return nil
+	}
+	return decodeBoolValues(p.boolValues)
+}
+
+// MarshalJSON encodes the fields of p along with its profile defaults, enum
+// values and lenient boolean values.
+func (p FieldParams) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.toJSON())
+}
+
+// fieldParams has the fields of FieldParams, without its methods.
+type fieldParams FieldParams
+
+// jsonFieldParams is the JSON encoding of FieldParams.
+type jsonFieldParams struct {
+	fieldParams
+	ProfileDefaults map[string]string `json:",omitempty"`
+	Enum            []string          `json:",omitempty"`
+	BoolValues      map[string]bool   `json:",omitempty"`
+}
+
+func (p FieldParams) toJSON() jsonFieldParams {
+	return jsonFieldParams{
+		fieldParams:     fieldParams(p),
+		ProfileDefaults: p.ProfileDefaults(),
+		Enum:            p.Enum(),
+		BoolValues:      p.BoolValues(),
+	}
 }
 
 func parseFieldParams(field reflect.StructField, opts Options) (FieldParams, error) {
 	ownKey, tags := parseKeyForOption(field.Tag.Get(opts.TagName))
 	if ownKey == "" && opts.UseFieldNameByDefault {
-		ownKey = toEnvName(field.Name)
+		ownKey = fieldNameToKey(field.Name, opts)
 	}
 
 	defaultValue, hasDefaultValue := field.Tag.Lookup(opts.DefaultValueTagName)
+	profileDefaults := lookupTagsWithPrefix(field.Tag, opts.DefaultValueTagName+".")
+	if value, ok := profileDefaults[opts.Profile]; ok && opts.Profile != "" {
+		defaultValue, hasDefaultValue = value, true
+	}
 
 	result := FieldParams{
 		OwnKey:          ownKey,
 		Key:             opts.Prefix + ownKey,
 		Required:        opts.RequiredIfNoDef,
+		KeyFile:         opts.UseKeyFile,
+		Sensitive:       isSecretType(field.Type),
 		DefaultValue:    defaultValue,
 		HasDefaultValue: hasDefaultValue,
+		profileDefaults: encodeStringMap(profileDefaults),
+		RequiredIf:      field.Tag.Get("envRequiredIf"),
+		RequiredWith:    field.Tag.Get("envRequiredWith"),
+		Exclusive:       field.Tag.Get("envExclusive"),
+		Description:     field.Tag.Get("envDescription"),
 		Ignored:         ownKey == "-",
 	}
 
+	result.enum = field.Tag.Get("envEnum")
+
+	if base, ok := field.Tag.Lookup("envBase"); ok {
+		b, err := strconv.Atoi(base)
+		if err != nil || b < 0 || b == 1 || b > 36 {
+			return FieldParams{}, withField(newParseError(field, fmt.Errorf("invalid envBase %q", base)), fieldPath(field, opts), result.Key, "")
+		}
+		result.Base, result.HasBase = b, true
+	} else if opts.IntegerLiterals {
+		result.HasBase = true
+	}
+
+	if opts.LenientBools {
+		result.LenientBool = true
+	}
+
 	for _, tag := range tags {
 		switch tag {
 		case "":
 			continue
 		case "file":
 			result.LoadFile = true
+		case "keyFile":
+			result.KeyFile = true
+		case "sensitive":
+			result.Sensitive = true
 		case "required":
 			result.Required = true
 		case "unset":
@@ -578,36 +1173,68 @@ func parseFieldParams(field reflect.StructField, opts Options) (FieldParams, err
 			result.NotEmpty = true
 		case "expand":
 			result.Expand = true
+		case "quoted":
+			result.Quoted = true
+		case "trimSpace":
+			result.TrimSpace = true
+		case "skipEmpty":
+			result.SkipEmpty = true
 		case "init":
 			result.Init = true
+		case "initIfSet":
+			result.InitIfSet = true
+		case "lenientBool":
+			result.LenientBool = true
 		case "-":
 			result.Ignored = true
 		default:
-			return FieldParams{}, newNoSupportedTagOptionError(tag)
+			return FieldParams{}, withField(newNoSupportedTagOptionError(tag), fieldPath(field, opts), result.Key, "")
 		}
 	}
 
+	if result.LenientBool {
+		result.boolValues = encodeBoolValues(boolValues(opts))
+	}
+
 	return result, nil
 }
 
 func get(fieldParams FieldParams, opts Options) (val string, err error) {
-	var exists, isDefault bool
+	var exists, isDefault, fromKeyFile bool
 
-	val, exists, isDefault = getOr(
-		fieldParams.Key,
-		fieldParams.DefaultValue,
-		fieldParams.HasDefaultValue,
-		opts.Environment,
-	)
+	if err := opts.dirFileError(fieldParams.Key); err != nil {
+		return "", err
+	}
+	if fieldParams.KeyFile {
+		if err := opts.dirFileError(fieldParams.Key + opts.KeyFileSuffix); err != nil {
+			return "", err
+		}
+	}
+
+	val, fromKeyFile, err = getFromKeyFile(fieldParams, opts)
+	if err != nil {
+		return "", err
+	}
 
-	if fieldParams.Expand {
+	if fromKeyFile {
+		exists = true
+	} else {
+		val, exists, isDefault = getOr(
+			fieldParams.Key,
+			fieldParams.DefaultValue,
+			fieldParams.HasDefaultValue,
+			opts.lookupEnv,
+		)
+	}
+
+	if fieldParams.Expand && !fromKeyFile {
 		val = os.Expand(val, opts.getRawEnv)
 	}
 
 	opts.rawEnvVars[fieldParams.Key] = val
 
 	if fieldParams.Unset {
-		defer os.Unsetenv(fieldParams.Key)
+		defer os.Unsetenv(opts.envKey(fieldParams.Key))
 	}
 
 	if fieldParams.Required && !exists && fieldParams.OwnKey != "" {
@@ -618,9 +1245,9 @@ func get(fieldParams FieldParams, opts Options) (val string, err error) {
 		return "", newEmptyVarError(fieldParams.Key)
 	}
 
-	if fieldParams.LoadFile && val != "" {
+	if fieldParams.LoadFile && val != "" && !fromKeyFile {
 		filename := val
-		val, err = getFromFile(filename)
+		val, err = getFromFile(filename, fieldParams, opts)
 		if err != nil {
 			return "", newLoadFileContentError(filename, fieldParams.Key, err)
 		}
@@ -634,19 +1261,216 @@ func get(fieldParams FieldParams, opts Options) (val string, err error) {
 	return val, err
 }
 
+// encodeStringMap encodes m as a string of quoted keys and values, sorted by
+// key, or "" if m is empty.
+func encodeStringMap(m map[string]string) string {
+	var sb strings.Builder
+	for _, k := range sortedKeys(m) {
+		sb.WriteString(strconv.Quote(k))
+		sb.WriteString(strconv.Quote(m[k]))
+	}
+	return sb.String()
+}
+
+// decodeStringMap decodes a string encoded with encodeStringMap, returning
+// nil if it is empty.
+func decodeStringMap(s string) map[string]string {
+	if s == "" {
+		return nil
+	}
+	m := map[string]string{}
+	for s != "" {
+		var kv [2]string
+		for i := range kv {
+			quoted, _ := strconv.QuotedPrefix(s)
+			s = s[len(quoted):]
+			kv[i], _ = strconv.Unquote(quoted)
+		}
+		m[kv[0]] = kv[1]
+	}
+	return m
+}
+
+// lookupTagsWithPrefix returns the values of all the tags whose key starts
+// with prefix, indexed by the rest of their key, or nil if there are none.
+// The tag is parsed the same way as reflect.StructTag.Lookup does.
+func lookupTagsWithPrefix(tag reflect.StructTag, prefix string) map[string]string {
+	var result map[string]string
+	for tag != "" {
+		// Skip leading space.
+		i := 0
+		for i < len(tag) && tag[i] == ' ' {
+			i++
+		}
+		tag = tag[i:]
+		if tag == "" {
+			break
+		}
+
+		// Scan to colon. A space, a quote or a control character is a syntax error.
+		i = 0
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+		if i == 0 || i+1 >= len(tag) || tag[i] != ':' || tag[i+1] != '"' {
+			break
+		}
+		name := string(tag[:i])
+		tag = tag[i+1:]
+
+		// Scan quoted string to find value.
+		i = 1
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(tag) {
+			break
+		}
+		qvalue := string(tag[:i+1])
+		tag = tag[i+1:]
+
+		if !strings.HasPrefix(name, prefix) || len(name) == len(prefix) {
+			continue
+		}
+		value, err := strconv.Unquote(qvalue)
+		if err != nil {
+			break
+		}
+		if result == nil {
+			result = map[string]string{}
+		}
+		result[strings.TrimPrefix(name, prefix)] = value
+	}
+	return result
+}
+
 // split the env tag's key into the expected key and desired option, if any.
 func parseKeyForOption(key string) (string, []string) {
 	opts := strings.Split(key, ",")
 	return opts[0], opts[1:]
 }
 
-func getFromFile(filename string) (value string, err error) {
-	b, err := os.ReadFile(filename)
-	return string(b), err
+// getFromKeyFile reads the value from the file named by KEY_FILE (or whatever
+// suffix is configured) when the field allows it and KEY itself is not set.
+func getFromKeyFile(fieldParams FieldParams, opts Options) (val string, ok bool, err error) {
+	if !fieldParams.KeyFile || fieldParams.OwnKey == "" {
+		return "", false, nil
+	}
+	if value, _ := opts.lookupEnv(fieldParams.Key); value != "" {
+		return "", false, nil
+	}
+
+	fileKey := fieldParams.Key + opts.KeyFileSuffix
+	filename, _ := opts.lookupEnv(fileKey)
+	if filename == "" {
+		return "", false, nil
+	}
+
+	val, err = getFromFile(filename, fieldParams, opts)
+	if err != nil {
+		return "", false, newLoadFileContentError(filename, fileKey, err)
+	}
+	return val, true, nil
 }
 
-func getOr(key, defaultValue string, defExists bool, envs map[string]string) (val string, exists, isDefault bool) {
-	value, exists := envs[key]
+func getFromFile(filename string, fieldParams FieldParams, opts Options) (value string, err error) {
+	f, err := openFile(filename, opts)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if fieldParams.Sensitive && opts.FileStrictPermissions && runtime.GOOS != "windows" {
+		info, err := f.Stat()
+		if err != nil {
+			return "", err
+		}
+		if perm := info.Mode().Perm(); perm&0o044 != 0 {
+			return "", fmt.Errorf("file is readable by group or others (%s)", perm)
+		}
+	}
+
+	value, err = readAll(f, opts.FileMaxSize)
+	if err != nil {
+		return "", err
+	}
+
+	if opts.FileTrimTrailingNewline {
+		value = trimTrailingNewline(value)
+	}
+	if opts.FileTrimTrailingSpace {
+		value = strings.TrimRightFunc(value, unicode.IsSpace)
+	}
+	return value, nil
+}
+
+func openFile(filename string, opts Options) (fs.File, error) {
+	if opts.FileSystem != nil {
+		name := path.Clean(filename)
+		if base := path.Clean(opts.FileBaseDir); opts.FileBaseDir != "" && base != "." &&
+			name != base && !strings.HasPrefix(name, base+"/") {
+			return nil, fmt.Errorf("file is outside of %q", opts.FileBaseDir)
+		}
+		return opts.FileSystem.Open(name)
+	}
+
+	if opts.FileBaseDir != "" {
+		if err := checkBaseDir(filename, opts.FileBaseDir); err != nil {
+			return nil, err
+		}
+	}
+	return os.Open(filename)
+}
+
+// checkBaseDir makes sure filename is inside dir once all symlinks are
+// resolved.
+func checkBaseDir(filename, dir string) error {
+	resolved, err := filepath.EvalSymlinks(filename)
+	if err != nil {
+		return err
+	}
+	resolved, err = filepath.Abs(resolved)
+	if err != nil {
+		return err
+	}
+	base, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return err
+	}
+	base, err = filepath.Abs(base)
+	if err != nil {
+		return err
+	}
+	rel, err := filepath.Rel(base, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("file is outside of %q", dir)
+	}
+	return nil
+}
+
+// readAll reads r until EOF, failing if it has more than maxSize bytes.
+// A maxSize of 0 means no limit.
+func readAll(r io.Reader, maxSize int64) (string, error) {
+	if maxSize <= 0 {
+		b, err := io.ReadAll(r)
+		return string(b), err
+	}
+
+	b, err := io.ReadAll(io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return "", err
+	}
+	if int64(len(b)) > maxSize {
+		return "", fmt.Errorf("file is larger than %d bytes", maxSize)
+	}
+	return string(b), nil
+}
+
+func getOr(key, defaultValue string, defExists bool, lookup func(string) (string, bool)) (val string, exists, isDefault bool) {
+	value, exists := lookup(key)
 	switch {
 	case (!exists || key == "") && defExists:
 		return defaultValue, true, true
@@ -659,7 +1483,20 @@ func getOr(key, defaultValue string, defExists bool, envs map[string]string) (va
 	return value, true, false
 }
 
-func set(field reflect.Value, sf reflect.StructField, value string, funcMap map[reflect.Type]ParserFunc) error {
+func set(field reflect.Value, sf reflect.StructField, value string, funcMap map[reflect.Type]ParserFunc, fieldParams FieldParams) error {
+	if w := asWrapper(field); w != nil {
+		if s, ok := w.(secret); ok && s.setBytes(value) {
+			return nil
+		}
+		inner := w.wrapped()
+		sf.Type = inner.Type()
+		if err := set(inner, sf, value, funcMap, fieldParams); err != nil {
+			return err
+		}
+		w.markSet()
+		return nil
+	}
+
 	if tm := asTextUnmarshaler(field); tm != nil {
 		if err := tm.UnmarshalText([]byte(value)); err != nil {
 			return newParseError(sf, err)
@@ -685,6 +1522,25 @@ func set(field reflect.Value, sf reflect.StructField, value string, funcMap map[
 		return nil
 	}
 
+	if fieldParams.HasBase {
+		if val, ok, err := parseInteger(typee, value, fieldParams.Base); ok {
+			if err != nil {
+				return newParseError(sf, err)
+			}
+			fieldee.Set(val)
+			return nil
+		}
+	}
+
+	if fieldParams.LenientBool && typee.Kind() == reflect.Bool {
+		b, err := parseLenientBool(value, fieldParams.BoolValues())
+		if err != nil {
+			return newParseError(sf, err)
+		}
+		fieldee.Set(reflect.ValueOf(b).Convert(typee))
+		return nil
+	}
+
 	parserFunc, ok = defaultBuiltInParsers[typee.Kind()]
 	if ok {
 		val, err := parserFunc(value)
@@ -697,107 +1553,186 @@ func set(field reflect.Value, sf reflect.StructField, value string, funcMap map[
 	}
 
 	switch field.Kind() {
-	case reflect.Slice:
-		return handleSlice(field, value, sf, funcMap)
+	case reflect.Slice, reflect.Array:
+		return handleSlice(field, value, sf, funcMap, fieldParams)
 	case reflect.Map:
-		return handleMap(field, value, sf, funcMap)
+		return handleMap(field, value, sf, funcMap, fieldParams)
 	}
 
 	return newNoParserError(sf)
 }
 
-func handleSlice(field reflect.Value, value string, sf reflect.StructField, funcMap map[reflect.Type]ParserFunc) error {
-	separator := sf.Tag.Get("envSeparator")
-	if separator == "" {
-		separator = ","
+func handleSlice(field reflect.Value, value string, sf reflect.StructField, funcMap map[reflect.Type]ParserFunc, fieldParams FieldParams) error {
+	if !hasParser(sf.Type, funcMap) {
+		return newNoParserError(sf)
 	}
-	parts := strings.Split(value, separator)
-
-	typee := sf.Type.Elem()
-	if typee.Kind() == reflect.Ptr {
-		typee = typee.Elem()
+	seps, err := newSeparators(sf, funcMap)
+	if err != nil {
+		return newParseError(sf, err)
 	}
 
-	if _, ok := reflect.New(typee).Interface().(encoding.TextUnmarshaler); ok {
-		return parseTextUnmarshalers(field, parts, sf)
+	result, err := parseCollection(sf.Type, value, seps, funcMap, fieldParams)
+	if err != nil {
+		return newParseError(sf, err)
 	}
+	field.Set(result)
+	return nil
+}
 
-	parserFunc, ok := funcMap[typee]
-	if !ok {
-		parserFunc, ok = defaultBuiltInParsers[typee.Kind()]
-		if !ok {
-			return newNoParserError(sf)
-		}
+func handleMap(field reflect.Value, value string, sf reflect.StructField, funcMap map[reflect.Type]ParserFunc, fieldParams FieldParams) error {
+	if !hasParser(sf.Type, funcMap) {
+		return newNoParserError(sf)
+	}
+	seps, err := newSeparators(sf, funcMap)
+	if err != nil {
+		return newParseError(sf, err)
 	}
 
-	result := reflect.MakeSlice(sf.Type, 0, len(parts))
-	for _, part := range parts {
-		r, err := parserFunc(part)
-		if err != nil {
-			return newParseError(sf, err)
-		}
-		v := reflect.ValueOf(r).Convert(typee)
-		if sf.Type.Elem().Kind() == reflect.Ptr {
-			v = reflect.New(typee)
-			v.Elem().Set(reflect.ValueOf(r).Convert(typee))
-		}
-		result = reflect.Append(result, v)
+	result, err := parseMap(sf.Type, value, seps, funcMap, fieldParams)
+	if err != nil {
+		return newParseError(sf, err)
 	}
 	field.Set(result)
 	return nil
 }
 
-func handleMap(field reflect.Value, value string, sf reflect.StructField, funcMap map[reflect.Type]ParserFunc) error {
-	keyType := sf.Type.Key()
-	keyParserFunc, ok := funcMap[keyType]
-	if !ok {
-		keyParserFunc, ok = defaultBuiltInParsers[keyType.Kind()]
-		if !ok {
-			return newNoParserError(sf)
-		}
+// parseCollection parses value into a new slice, array or map of type typ.
+func parseCollection(typ reflect.Type, value string, seps separators, funcMap map[reflect.Type]ParserFunc, fieldParams FieldParams) (reflect.Value, error) {
+	switch typ.Kind() {
+	case reflect.Map:
+		return parseMap(typ, value, seps, funcMap, fieldParams)
+	case reflect.Array:
+		return parseArray(typ, value, seps, funcMap, fieldParams)
 	}
+	return parseSlice(typ, value, seps, funcMap, fieldParams)
+}
 
-	elemType := sf.Type.Elem()
-	elemParserFunc, ok := funcMap[elemType]
-	if !ok {
-		elemParserFunc, ok = defaultBuiltInParsers[elemType.Kind()]
-		if !ok {
-			return newNoParserError(sf)
+func parseSlice(typ reflect.Type, value string, seps separators, funcMap map[reflect.Type]ParserFunc, fieldParams FieldParams) (reflect.Value, error) {
+	elemType := typ.Elem()
+	if isNestedCollection(elemType, funcMap) {
+		parts := splitRawItems(value, seps.items[0], fieldParams)
+		result := reflect.MakeSlice(typ, 0, len(parts))
+		for _, part := range parts {
+			elem, err := parseCollection(elemType, part, seps.next(typ), funcMap, fieldParams)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			result = reflect.Append(result, elem)
 		}
+		return result, nil
 	}
 
-	separator := sf.Tag.Get("envSeparator")
-	if separator == "" {
-		separator = ","
+	parts, err := splitItems(value, seps.items[0], fieldParams)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	result := reflect.MakeSlice(typ, 0, len(parts))
+	for _, part := range parts {
+		elem, err := parseItem(elemType, part, funcMap, fieldParams)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		result = reflect.Append(result, elem)
 	}
+	return result, nil
+}
 
-	keyValSeparator := sf.Tag.Get("envKeyValSeparator")
-	if keyValSeparator == "" {
-		keyValSeparator = ":"
+// parseArray parses value into a new array of type typ, which must have
+// exactly as many items.
+func parseArray(typ reflect.Type, value string, seps separators, funcMap map[reflect.Type]ParserFunc, fieldParams FieldParams) (reflect.Value, error) {
+	items, err := parseSlice(reflect.SliceOf(typ.Elem()), value, seps, funcMap, fieldParams)
+	if err != nil {
+		return reflect.Value{}, err
 	}
+	if items.Len() != typ.Len() {
+		return reflect.Value{}, fmt.Errorf("expected %d items, got %d", typ.Len(), items.Len())
+	}
+
+	result := reflect.New(typ).Elem()
+	reflect.Copy(result, items)
+	return result, nil
+}
+
+func parseMap(typ reflect.Type, value string, seps separators, funcMap map[reflect.Type]ParserFunc, fieldParams FieldParams) (reflect.Value, error) {
+	elemType := typ.Elem()
+	nested := isNestedCollection(elemType, funcMap)
 
-	result := reflect.MakeMap(sf.Type)
-	for _, part := range strings.Split(value, separator) {
-		pairs := strings.SplitN(part, keyValSeparator, 2)
-		if len(pairs) != 2 {
-			return newParseError(sf, fmt.Errorf(`%q should be in "key%svalue" format`, part, keyValSeparator))
+	result := reflect.MakeMap(typ)
+	for _, part := range splitRawItems(value, seps.items[0], fieldParams) {
+		rawKey, rawElem, err := splitRawPair(part, seps.keyVals[0], fieldParams)
+		if err != nil {
+			return reflect.Value{}, err
 		}
 
-		key, err := keyParserFunc(pairs[0])
+		if rawKey, err = unquoteItem(rawKey, fieldParams); err != nil {
+			return reflect.Value{}, err
+		}
+		key, err := parseItem(typ.Key(), rawKey, funcMap, fieldParams)
 		if err != nil {
-			return newParseError(sf, err)
+			return reflect.Value{}, err
 		}
 
-		elem, err := elemParserFunc(pairs[1])
+		var elem reflect.Value
+		if nested {
+			elem, err = parseCollection(elemType, rawElem, seps.next(typ), funcMap, fieldParams)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+		} else {
+			if rawElem, err = unquoteItem(rawElem, fieldParams); err != nil {
+				return reflect.Value{}, err
+			}
+			if elem, err = parseItem(elemType, rawElem, funcMap, fieldParams); err != nil {
+				return reflect.Value{}, err
+			}
+		}
+
+		result.SetMapIndex(key, elem)
+	}
+	return result, nil
+}
+
+// parseItem parses an item of a slice or array, or a key or value of a map, of
+// type typ, which may be a pointer.
+func parseItem(typ reflect.Type, value string, funcMap map[reflect.Type]ParserFunc, fieldParams FieldParams) (reflect.Value, error) {
+	if typ.Kind() == reflect.Ptr {
+		elem, err := parseItem(typ.Elem(), value, funcMap, fieldParams)
 		if err != nil {
-			return newParseError(sf, err)
+			return reflect.Value{}, err
 		}
+		ptr := reflect.New(typ.Elem())
+		ptr.Elem().Set(elem)
+		return ptr, nil
+	}
 
-		result.SetMapIndex(reflect.ValueOf(key).Convert(keyType), reflect.ValueOf(elem).Convert(elemType))
+	if tm, ok := reflect.New(typ).Interface().(encoding.TextUnmarshaler); ok {
+		if err := tm.UnmarshalText([]byte(value)); err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(tm).Elem(), nil
 	}
 
-	field.Set(result)
-	return nil
+	parserFunc, ok := funcMap[typ]
+	if !ok && fieldParams.HasBase {
+		if v, ok, err := parseInteger(typ, value, fieldParams.Base); ok {
+			return v, err
+		}
+	}
+	if !ok && fieldParams.LenientBool && typ.Kind() == reflect.Bool {
+		b, err := parseLenientBool(value, fieldParams.BoolValues())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(b).Convert(typ), nil
+	}
+	if !ok {
+		parserFunc = defaultBuiltInParsers[typ.Kind()]
+	}
+	v, err := parserFunc(value)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return reflect.ValueOf(v).Convert(typ), nil
 }
 
 func asTextUnmarshaler(field reflect.Value) encoding.TextUnmarshaler {
@@ -816,32 +1751,6 @@ func asTextUnmarshaler(field reflect.Value) encoding.TextUnmarshaler {
 	return tm
 }
 
-func parseTextUnmarshalers(field reflect.Value, data []string, sf reflect.StructField) error {
-	s := len(data)
-	elemType := field.Type().Elem()
-	slice := reflect.MakeSlice(reflect.SliceOf(elemType), s, s)
-	for i, v := range data {
-		sv := slice.Index(i)
-		kind := sv.Kind()
-		if kind == reflect.Ptr {
-			sv = reflect.New(elemType.Elem())
-		} else {
-			sv = sv.Addr()
-		}
-		tm := sv.Interface().(encoding.TextUnmarshaler)
-		if err := tm.UnmarshalText([]byte(v)); err != nil {
-			return newParseError(sf, err)
-		}
-		if kind == reflect.Ptr {
-			slice.Index(i).Set(sv)
-		}
-	}
-
-	field.Set(slice)
-
-	return nil
-}
-
 // ToMap Converts list of env vars as provided by os.Environ() to map you
 // can use as Options.Environment field
 func ToMap(env []string) map[string]string {
@@ -0,0 +1,147 @@
+package env
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DirSource is a directory in which every file is a variable: the file name is
+// the key, and its content is the value. Files are only read when their key is
+// looked up, so unreadable files no field uses are ignored.
+//
+// This is the layout used by Kubernetes ConfigMap and Secret volumes, Docker
+// secrets (/run/secrets), and systemd credentials ($CREDENTIALS_DIRECTORY).
+type DirSource struct {
+	// Path of the directory.
+	// Directories that do not exist are ignored.
+	Path string
+
+	// TrimTrailingNewline removes a single trailing "\n" or "\r\n" from the
+	// contents of each file.
+	TrimTrailingNewline bool
+}
+
+// withDirs returns a copy of env with the keys of the files of dirs, along
+// with these files, which are only read when their key is looked up. Keys
+// already present in env take precedence, and so do the ones from directories
+// listed first.
+func withDirs(env map[string]string, dirs []DirSource, maxSize int64) (map[string]string, dirFiles, error) {
+	if len(dirs) == 0 {
+		return env, nil, nil
+	}
+
+	result := make(map[string]string, len(env))
+	for k, v := range env {
+		result[k] = v
+	}
+
+	files := dirFiles{}
+	for _, dir := range dirs {
+		if err := listDir(result, files, dir, maxSize); err != nil {
+			return nil, nil, err
+		}
+	}
+	return result, files, nil
+}
+
+// listDir adds the keys of the files of dir to env, with an empty value, and
+// the files to files.
+func listDir(env map[string]string, files dirFiles, dir DirSource, maxSize int64) error {
+	if dir.Path == "" {
+		return nil
+	}
+
+	entries, err := os.ReadDir(dir.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return newLoadFileContentError(dir.Path, "", err)
+	}
+
+	for _, entry := range entries {
+		key := entry.Name()
+		// Kubernetes keeps the actual files in "..data" and timestamped
+		// "..2006_01_02..." directories, and links each key to them.
+		if strings.HasPrefix(key, ".") || entry.IsDir() {
+			continue
+		}
+		if _, ok := env[key]; ok {
+			continue
+		}
+
+		env[key] = ""
+		files[key] = &dirFile{
+			key:      key,
+			filename: filepath.Join(dir.Path, key),
+			trim:     dir.TrimTrailingNewline,
+			maxSize:  maxSize,
+		}
+	}
+	return nil
+}
+
+// dirFiles are the files of Dirs, indexed by their key.
+type dirFiles map[string]*dirFile
+
+// dirFile is a file of a DirSource, read the first time its key is looked up,
+// so the files no field uses are never read.
+type dirFile struct {
+	key      string
+	filename string
+	trim     bool
+	maxSize  int64
+
+	loaded bool
+	value  string
+	ok     bool
+	err    error
+}
+
+// load returns the content of the file, and whether it is a regular file,
+// reading it only once.
+func (f *dirFile) load() (string, bool, error) {
+	if !f.loaded {
+		f.loaded = true
+		f.value, f.ok, f.err = f.read()
+	}
+	return f.value, f.ok, f.err
+}
+
+func (f *dirFile) read() (string, bool, error) {
+	info, err := os.Stat(f.filename) // follows symlinks
+	if err != nil {
+		return "", false, newLoadFileContentError(f.filename, f.key, err)
+	}
+	if !info.Mode().IsRegular() {
+		return "", false, nil
+	}
+
+	value, err := readFile(f.filename, f.maxSize)
+	if err != nil {
+		return "", false, newLoadFileContentError(f.filename, f.key, err)
+	}
+	if f.trim {
+		value = trimTrailingNewline(value)
+	}
+	return value, true, nil
+}
+
+func trimTrailingNewline(s string) string {
+	if !strings.HasSuffix(s, "\n") {
+		return s
+	}
+	return strings.TrimSuffix(s[:len(s)-1], "\r")
+}
+
+func readFile(filename string, maxSize int64) (string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	return readAll(f, maxSize)
+}
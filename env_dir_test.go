@@ -0,0 +1,137 @@
+package env
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestDirSource(t *testing.T) {
+	type Config struct {
+		Host     string `env:"HOST"`
+		Password string `env:"PASSWORD"`
+		Token    string `env:"TOKEN"`
+	}
+
+	dir := t.TempDir()
+	isNoErr(t, os.WriteFile(filepath.Join(dir, "PASSWORD"), []byte("hunter2\n"), 0o600))
+	isNoErr(t, os.WriteFile(filepath.Join(dir, "TOKEN"), []byte("abc\r\n"), 0o600))
+	isNoErr(t, os.WriteFile(filepath.Join(dir, "HOST"), []byte("from-file"), 0o600))
+	isNoErr(t, os.Mkdir(filepath.Join(dir, "SUBDIR"), 0o700))
+
+	t.Run("trim", func(t *testing.T) {
+		cfg, err := ParseAsWithOptions[Config](Options{
+			Environment: map[string]string{"HOST": "from-env"},
+			Dirs:        []DirSource{{Path: dir, TrimTrailingNewline: true}},
+		})
+		isNoErr(t, err)
+		isEqual(t, "from-env", cfg.Host)
+		isEqual(t, "hunter2", cfg.Password)
+		isEqual(t, "abc", cfg.Token)
+	})
+
+	t.Run("no trim", func(t *testing.T) {
+		cfg, err := ParseAsWithOptions[Config](Options{
+			Environment: map[string]string{},
+			Dirs:        []DirSource{{Path: dir}},
+		})
+		isNoErr(t, err)
+		isEqual(t, "from-file", cfg.Host)
+		isEqual(t, "hunter2\n", cfg.Password)
+	})
+
+	t.Run("first dir wins", func(t *testing.T) {
+		other := t.TempDir()
+		isNoErr(t, os.WriteFile(filepath.Join(other, "HOST"), []byte("other"), 0o600))
+		cfg, err := ParseAsWithOptions[Config](Options{
+			Environment: map[string]string{},
+			Dirs:        []DirSource{{Path: other}, {Path: dir}},
+		})
+		isNoErr(t, err)
+		isEqual(t, "other", cfg.Host)
+		isEqual(t, "hunter2\n", cfg.Password)
+	})
+
+	t.Run("max size", func(t *testing.T) {
+		_, err := ParseAsWithOptions[Config](Options{
+			Environment: map[string]string{},
+			FileMaxSize: 4,
+			Dirs:        []DirSource{{Path: dir}},
+		})
+		isTrue(t, errors.Is(err, LoadFileContentError{}))
+	})
+
+	t.Run("missing dir", func(t *testing.T) {
+		cfg, err := ParseAsWithOptions[Config](Options{
+			Environment: map[string]string{"HOST": "from-env"},
+			Dirs:        []DirSource{{Path: filepath.Join(dir, "nope")}, {Path: ""}},
+		})
+		isNoErr(t, err)
+		isEqual(t, "from-env", cfg.Host)
+	})
+}
+
+func TestDirSourceKubernetesLayout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks")
+	}
+
+	type Config struct {
+		Password string `env:"DB_PASSWORD,required"`
+		Users    []struct {
+			Name string `env:"NAME"`
+		} `envPrefix:"USERS"`
+	}
+
+	dir := t.TempDir()
+	data := filepath.Join(dir, "..2024_01_01_00_00_00.000000000")
+	isNoErr(t, os.Mkdir(data, 0o700))
+	isNoErr(t, os.WriteFile(filepath.Join(data, "APP_DB_PASSWORD"), []byte("s3cr3t\n"), 0o600))
+	isNoErr(t, os.WriteFile(filepath.Join(data, "APP_USERS_0_NAME"), []byte("carlos"), 0o600))
+	isNoErr(t, os.WriteFile(filepath.Join(data, "DB_PASSWORD"), []byte("unprefixed"), 0o600))
+	isNoErr(t, os.Symlink(filepath.Base(data), filepath.Join(dir, "..data")))
+	for _, key := range []string{"APP_DB_PASSWORD", "APP_USERS_0_NAME", "DB_PASSWORD"} {
+		isNoErr(t, os.Symlink(filepath.Join("..data", key), filepath.Join(dir, key)))
+	}
+
+	cfg, err := ParseAsWithOptions[Config](Options{
+		Environment: map[string]string{},
+		Prefix:      "APP_",
+		Dirs:        []DirSource{{Path: dir, TrimTrailingNewline: true}},
+	})
+	isNoErr(t, err)
+	isEqual(t, "s3cr3t", cfg.Password)
+	isEqual(t, 1, len(cfg.Users))
+	isEqual(t, "carlos", cfg.Users[0].Name)
+}
+
+func TestDirSourceError(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks")
+	}
+
+	type Config struct {
+		Foo string `env:"FOO"`
+	}
+
+	dir := t.TempDir()
+	isNoErr(t, os.Symlink(filepath.Join(dir, "nope"), filepath.Join(dir, "FOO")))
+
+	_, err := ParseAsWithOptions[Config](Options{Environment: map[string]string{}, Dirs: []DirSource{{Path: dir}}})
+	isTrue(t, errors.Is(err, LoadFileContentError{}))
+
+	// Files no field uses are not read.
+	type Other struct {
+		Bar string `env:"BAR"`
+	}
+	isNoErr(t, os.WriteFile(filepath.Join(dir, "BAR"), []byte("bar"), 0o600))
+	cfg, err := ParseAsWithOptions[Other](Options{Environment: map[string]string{}, Dirs: []DirSource{{Path: dir}}})
+	isNoErr(t, err)
+	isEqual(t, "bar", cfg.Bar)
+
+	// Introspection does not read the directories.
+	_, err = GetFieldParamsWithOptions(&Config{}, Options{Environment: map[string]string{}, Dirs: []DirSource{{Path: dir}}})
+	isNoErr(t, err)
+}
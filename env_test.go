@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/netip"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -15,6 +16,7 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"testing/fstest"
 	"time"
 )
 
@@ -574,6 +576,130 @@ func TestParseMapCustomValueTypeError(t *testing.T) {
 	isTrue(t, errors.Is(err, ParseError{}))
 }
 
+func TestParseMapParity(t *testing.T) {
+	type config struct {
+		Addrs     map[netip.Addr]string        `env:"ADDRS" envKeyValSeparator:"="`
+		Timeouts  map[string]unmarshaler       `env:"TIMEOUTS"`
+		Limits    map[string]*int              `env:"LIMITS"`
+		Durations map[string]time.Duration     `env:"DURATIONS"`
+		Endpoints map[string]url.URL           `env:"ENDPOINTS" envSeparator:" "`
+		Levels    map[level]*unmarshaler       `env:"LEVELS"`
+		Pointers  map[string]*time.Duration    `env:"POINTERS"`
+		Nested    map[string][]*unmarshaler    `env:"NESTED" envSeparator:";,"`
+		Arrays    map[string][2]int            `env:"ARRAYS" envSeparator:";,"`
+		Locations map[string]time.Location     `env:"LOCATIONS"`
+		Texts     map[unmarshaler]unmarshaler  `env:"TEXTS"`
+		Custom    map[string]map[string]string `env:"CUSTOM"`
+	}
+
+	cfg, err := ParseAsWithOptions[config](Options{
+		Environment: map[string]string{
+			"ADDRS":     "127.0.0.1=local,::1=local6",
+			"TIMEOUTS":  "read:1s,write:2m",
+			"LIMITS":    "cpu:2",
+			"DURATIONS": "read:1s",
+			"ENDPOINTS": "api:https://example.com/api",
+			"LEVELS":    "debug:1s,info:2s",
+			"POINTERS":  "read:3s",
+			"NESTED":    "a:1s,2s;b:3s",
+			"ARRAYS":    "a:1,2",
+			"LOCATIONS": "utc:UTC",
+			"TEXTS":     "1s:2s",
+			"CUSTOM":    "x:a=b",
+		},
+		FuncMap: map[reflect.Type]ParserFunc{
+			reflect.TypeOf(map[string]string{}): func(v string) (interface{}, error) {
+				k, val, _ := strings.Cut(v, "=")
+				return map[string]string{k: val}, nil
+			},
+		},
+	})
+	isNoErr(t, err)
+	isEqual(t, map[netip.Addr]string{
+		netip.MustParseAddr("127.0.0.1"): "local",
+		netip.MustParseAddr("::1"):       "local6",
+	}, cfg.Addrs)
+	isEqual(t, map[string]unmarshaler{"read": {time.Second}, "write": {2 * time.Minute}}, cfg.Timeouts)
+	isEqual(t, 2, *cfg.Limits["cpu"])
+	isEqual(t, map[string]time.Duration{"read": time.Second}, cfg.Durations)
+	isEqual(t, "/api", cfg.Endpoints["api"].Path)
+	isEqual(t, time.Second, cfg.Levels[0].Duration)
+	isEqual(t, 2*time.Second, cfg.Levels[1].Duration)
+	isEqual(t, 3*time.Second, *cfg.Pointers["read"])
+	isEqual(t, 2, len(cfg.Nested["a"]))
+	isEqual(t, 2*time.Second, cfg.Nested["a"][1].Duration)
+	isEqual(t, map[string][2]int{"a": {1, 2}}, cfg.Arrays)
+	utc := cfg.Locations["utc"]
+	isEqual(t, "UTC", utc.String())
+	isEqual(t, map[unmarshaler]unmarshaler{{time.Second}: {2 * time.Second}}, cfg.Texts)
+	isEqual(t, map[string]map[string]string{"x": {"a": "b"}}, cfg.Custom)
+}
+
+func TestParseArrays(t *testing.T) {
+	type config struct {
+		Ints       [3]int              `env:"INTS"`
+		Strings    [2]string           `env:"STRINGS" envDefault:"a,b"`
+		Ptrs       [2]*int             `env:"PTRS"`
+		Durations  [1]time.Duration    `env:"DURATIONS"`
+		Texts      [2]unmarshaler      `env:"TEXTS"`
+		Grid       [2][2]int           `env:"GRID" envSeparator:";,"`
+		Slices     [2][]string         `env:"SLICES" envSeparator:";,"`
+		Optional   Optional[[2]string] `env:"OPTIONAL"`
+		Unset      [2]int              `env:"UNSET"`
+		Bytes      [4]byte             `env:"BYTES"`
+		WithSpaces [2]int              `env:"WITH_SPACES,trimSpace"`
+	}
+
+	cfg, err := ParseAsWithOptions[config](Options{Environment: map[string]string{
+		"INTS":        "1,2,3",
+		"PTRS":        "4,5",
+		"DURATIONS":   "1s",
+		"TEXTS":       "1s,2s",
+		"GRID":        "1,2;3,4",
+		"SLICES":      "a,b;c",
+		"OPTIONAL":    "x,y",
+		"BYTES":       "1,2,3,4",
+		"WITH_SPACES": " 8 , 9 ",
+	}})
+	isNoErr(t, err)
+	isEqual(t, [3]int{1, 2, 3}, cfg.Ints)
+	isEqual(t, [2]string{"a", "b"}, cfg.Strings)
+	isEqual(t, 4, *cfg.Ptrs[0])
+	isEqual(t, 5, *cfg.Ptrs[1])
+	isEqual(t, [1]time.Duration{time.Second}, cfg.Durations)
+	isEqual(t, [2]unmarshaler{{time.Second}, {2 * time.Second}}, cfg.Texts)
+	isEqual(t, [2][2]int{{1, 2}, {3, 4}}, cfg.Grid)
+	isEqual(t, [2][]string{{"a", "b"}, {"c"}}, cfg.Slices)
+	isEqual(t, [2]string{"x", "y"}, cfg.Optional.Or([2]string{}))
+	isEqual(t, [2]int{}, cfg.Unset)
+	isEqual(t, [4]byte{1, 2, 3, 4}, cfg.Bytes)
+	isEqual(t, [2]int{8, 9}, cfg.WithSpaces)
+	isNoErr(t, Check[config](Options{}))
+}
+
+func TestParseArraysErrors(t *testing.T) {
+	type config struct {
+		Short   [3]int       `env:"SHORT"`
+		Long    [1]string    `env:"LONG"`
+		Invalid [2]int       `env:"INVALID"`
+		Chans   [2]chan int  `env:"CHANS"`
+		Values  map[int]bool `env:"VALUES"`
+	}
+
+	_, err := ParseAsWithOptions[config](Options{Environment: map[string]string{
+		"SHORT":   "1,2",
+		"LONG":    "a,b",
+		"INVALID": "1,x",
+		"CHANS":   "1,2",
+		"VALUES":  "1:maybe",
+	}})
+	isErrorWithMessage(t, err, `env: parse error on field "Short" of type "[3]int": expected 3 items, got 2; `+
+		`parse error on field "Long" of type "[1]string": expected 1 items, got 2; `+
+		`parse error on field "Invalid" of type "[2]int": strconv.ParseInt: parsing "x": invalid syntax; `+
+		`no parser found for field "Chans" of type "[2]chan int"; `+
+		`parse error on field "Values" of type "map[int]bool": strconv.ParseBool: parsing "maybe": invalid syntax`)
+}
+
 func TestSetenvAndTagOptsChain(t *testing.T) {
 	type config struct {
 		Key1 string `mytag:"KEY1,required"`
@@ -626,6 +752,74 @@ func TestParsesEnvInner_WhenInnerStructPointerIsNil(t *testing.T) {
 	isEqual(t, uint(8), cfg.InnerStruct.Number)
 }
 
+func TestInitIfSet(t *testing.T) {
+	type Redis struct {
+		Addr     string `env:"ADDR,required"`
+		Password string `env:"PASSWORD,keyFile"`
+	}
+	type Config struct {
+		Redis    *Redis `env:",initIfSet" envPrefix:"REDIS_"`
+		NoPrefix *Redis `env:",initIfSet"`
+		Init     *Redis `env:",init" envPrefix:"INIT_"`
+	}
+
+	t.Run("unset", func(t *testing.T) {
+		var cfg Config
+		err := ParseWithOptions(&cfg, Options{Environment: map[string]string{}})
+		isErrorWithMessage(t, err, `env: required environment variable "INIT_ADDR" is not set`)
+		isTrue(t, cfg.Redis == nil)
+		isTrue(t, cfg.NoPrefix == nil)
+	})
+
+	t.Run("set", func(t *testing.T) {
+		var cfg Config
+		err := ParseWithOptions(&cfg, Options{Environment: map[string]string{
+			"REDIS_ADDR": "localhost:6379",
+			"INIT_ADDR":  "localhost:6380",
+		}})
+		isNoErr(t, err)
+		isEqual(t, &Redis{Addr: "localhost:6379"}, cfg.Redis)
+		isEqual(t, &Redis{Addr: "localhost:6380"}, cfg.Init)
+		isTrue(t, cfg.NoPrefix == nil)
+	})
+
+	t.Run("partially set", func(t *testing.T) {
+		var cfg Config
+		err := ParseWithOptions(&cfg, Options{Environment: map[string]string{
+			"REDIS_PASSWORD": "hunter2",
+			"INIT_ADDR":      "localhost:6380",
+			"ADDR":           "",
+		}})
+		isErrorWithMessage(t, err, `env: required environment variable "REDIS_ADDR" is not set`)
+		isEqual(t, &Redis{Addr: "", Password: "hunter2"}, cfg.Redis)
+		isEqual(t, &Redis{}, cfg.NoPrefix)
+	})
+
+	t.Run("key file", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "password")
+		isNoErr(t, os.WriteFile(file, []byte("hunter2"), 0o600))
+
+		var cfg Config
+		err := ParseWithOptions(&cfg, Options{Environment: map[string]string{
+			"PASSWORD_FILE": file,
+			"INIT_ADDR":     "localhost:6380",
+		}})
+		isErrorWithMessage(t, err, `env: required environment variable "ADDR" is not set`)
+		isTrue(t, cfg.Redis == nil)
+		isEqual(t, &Redis{Password: "hunter2"}, cfg.NoPrefix)
+	})
+
+	t.Run("already initialized", func(t *testing.T) {
+		cfg := Config{Redis: &Redis{Addr: "localhost:6379"}}
+		err := ParseWithOptions(&cfg, Options{Environment: map[string]string{
+			"REDIS_ADDR": "localhost:6380",
+			"INIT_ADDR":  "localhost:6380",
+		}})
+		isNoErr(t, err)
+		isEqual(t, &Redis{Addr: "localhost:6380"}, cfg.Redis)
+	})
+}
+
 func TestParsesEnvInnerFails(t *testing.T) {
 	type config struct {
 		Foo struct {
@@ -853,12 +1047,12 @@ func TestParseStructWithInvalidFieldKind(t *testing.T) {
 
 func TestUnsupportedSliceType(t *testing.T) {
 	type config struct {
-		WontWork []map[int]int `env:"WONTWORK"`
+		WontWork []map[int]chan int `env:"WONTWORK"`
 	}
 
 	t.Setenv("WONTWORK", "1,2,3")
 	err := Parse(&config{})
-	isErrorWithMessage(t, err, `env: no parser found for field "WontWork" of type "[]map[int]int"`)
+	isErrorWithMessage(t, err, `env: no parser found for field "WontWork" of type "[]map[int]chan int"`)
 	isTrue(t, errors.Is(err, NoParserError{}))
 }
 
@@ -1515,6 +1709,256 @@ func TestFileWithDefault(t *testing.T) {
 	isEqual(t, "secret", cfg.SecretKey)
 }
 
+func TestKeyFile(t *testing.T) {
+	type config struct {
+		Password string `env:"PASSWORD,keyFile,required"`
+		User     string `env:"USER,keyFile" envDefault:"admin"`
+	}
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "password")
+	isNoErr(t, os.WriteFile(file, []byte("secret"), 0o600))
+
+	t.Run("from file", func(t *testing.T) {
+		cfg, err := ParseAsWithOptions[config](Options{Environment: map[string]string{
+			"PASSWORD_FILE": file,
+		}})
+		isNoErr(t, err)
+		isEqual(t, "secret", cfg.Password)
+		isEqual(t, "admin", cfg.User)
+	})
+
+	t.Run("variable wins", func(t *testing.T) {
+		cfg, err := ParseAsWithOptions[config](Options{Environment: map[string]string{
+			"PASSWORD":      "inline",
+			"PASSWORD_FILE": file,
+			"USER_FILE":     file,
+		}})
+		isNoErr(t, err)
+		isEqual(t, "inline", cfg.Password)
+		isEqual(t, "secret", cfg.User)
+	})
+
+	t.Run("custom suffix", func(t *testing.T) {
+		cfg, err := ParseAsWithOptions[config](Options{
+			KeyFileSuffix: "_PATH",
+			Environment:   map[string]string{"PASSWORD_PATH": file},
+		})
+		isNoErr(t, err)
+		isEqual(t, "secret", cfg.Password)
+	})
+
+	t.Run("not set", func(t *testing.T) {
+		_, err := ParseAsWithOptions[config](Options{Environment: map[string]string{}})
+		isErrorWithMessage(t, err, `env: required environment variable "PASSWORD" is not set`)
+	})
+
+	t.Run("bad file", func(t *testing.T) {
+		_, err := ParseAsWithOptions[config](Options{Environment: map[string]string{
+			"PASSWORD_FILE": filepath.Join(dir, "nope"),
+		}})
+		isTrue(t, errors.Is(err, LoadFileContentError{}))
+	})
+}
+
+func TestUseKeyFile(t *testing.T) {
+	type config struct {
+		Password string `env:"PASSWORD"`
+		Secret   string `env:"SECRET,file"`
+	}
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "password")
+	isNoErr(t, os.WriteFile(file, []byte("secret"), 0o600))
+
+	cfg, err := ParseAsWithOptions[config](Options{
+		UseKeyFile: true,
+		Environment: map[string]string{
+			"PASSWORD_FILE": file,
+			"SECRET_FILE":   file,
+		},
+	})
+	isNoErr(t, err)
+	isEqual(t, "secret", cfg.Password)
+	isEqual(t, "secret", cfg.Secret)
+}
+
+func TestFileMaxSize(t *testing.T) {
+	type config struct {
+		SecretKey string `env:"SECRET_KEY,file"`
+	}
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "sec_key")
+	isNoErr(t, os.WriteFile(file, []byte("secret"), 0o600))
+
+	cfg, err := ParseAsWithOptions[config](Options{
+		FileMaxSize: 6,
+		Environment: map[string]string{"SECRET_KEY": file},
+	})
+	isNoErr(t, err)
+	isEqual(t, "secret", cfg.SecretKey)
+
+	_, err = ParseAsWithOptions[config](Options{
+		FileMaxSize: 5,
+		Environment: map[string]string{"SECRET_KEY": file},
+	})
+	isErrorWithMessage(t, err, fmt.Sprintf("env: could not load content of file %q from variable SECRET_KEY: file is larger than 5 bytes", file))
+	isTrue(t, errors.Is(err, LoadFileContentError{}))
+
+	if runtime.GOOS != "windows" {
+		_, err = ParseAsWithOptions[config](Options{
+			FileMaxSize: 1024,
+			Environment: map[string]string{"SECRET_KEY": "/dev/zero"},
+		})
+		isTrue(t, errors.Is(err, LoadFileContentError{}))
+	}
+}
+
+func TestFileTrim(t *testing.T) {
+	type config struct {
+		SecretKey string `env:"SECRET_KEY,file"`
+	}
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "sec_key")
+	isNoErr(t, os.WriteFile(file, []byte(" secret \t\n\n"), 0o600))
+
+	for name, tt := range map[string]struct {
+		opts     Options
+		expected string
+	}{
+		"none":    {Options{}, " secret \t\n\n"},
+		"newline": {Options{FileTrimTrailingNewline: true}, " secret \t\n"},
+		"space":   {Options{FileTrimTrailingSpace: true}, " secret"},
+		"both":    {Options{FileTrimTrailingNewline: true, FileTrimTrailingSpace: true}, " secret"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			tt.opts.Environment = map[string]string{"SECRET_KEY": file}
+			cfg, err := ParseAsWithOptions[config](tt.opts)
+			isNoErr(t, err)
+			isEqual(t, tt.expected, cfg.SecretKey)
+		})
+	}
+}
+
+func TestFileStrictPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permissions")
+	}
+
+	type config struct {
+		SecretKey string `env:"SECRET_KEY,file,sensitive"`
+		Other     string `env:"OTHER,file"`
+	}
+
+	dir := t.TempDir()
+	private := filepath.Join(dir, "private")
+	isNoErr(t, os.WriteFile(private, []byte("secret"), 0o600))
+	public := filepath.Join(dir, "public")
+	isNoErr(t, os.WriteFile(public, []byte("public"), 0o644))
+
+	cfg, err := ParseAsWithOptions[config](Options{
+		FileStrictPermissions: true,
+		Environment:           map[string]string{"SECRET_KEY": private, "OTHER": public},
+	})
+	isNoErr(t, err)
+	isEqual(t, "secret", cfg.SecretKey)
+	isEqual(t, "public", cfg.Other)
+
+	_, err = ParseAsWithOptions[config](Options{
+		FileStrictPermissions: true,
+		Environment:           map[string]string{"SECRET_KEY": public},
+	})
+	isErrorWithMessage(t, err, fmt.Sprintf("env: could not load content of file %q from variable SECRET_KEY: file is readable by group or others (-rw-r--r--)", public))
+
+	_, err = ParseAsWithOptions[config](Options{
+		Environment: map[string]string{"SECRET_KEY": public},
+	})
+	isNoErr(t, err)
+}
+
+func TestFileBaseDir(t *testing.T) {
+	type config struct {
+		SecretKey string `env:"SECRET_KEY,file"`
+	}
+
+	dir := t.TempDir()
+	base := filepath.Join(dir, "secrets")
+	isNoErr(t, os.Mkdir(base, 0o700))
+	inside := filepath.Join(base, "inside")
+	isNoErr(t, os.WriteFile(inside, []byte("inside"), 0o600))
+	outside := filepath.Join(dir, "outside")
+	isNoErr(t, os.WriteFile(outside, []byte("outside"), 0o600))
+
+	cfg, err := ParseAsWithOptions[config](Options{
+		FileBaseDir: base,
+		Environment: map[string]string{"SECRET_KEY": inside},
+	})
+	isNoErr(t, err)
+	isEqual(t, "inside", cfg.SecretKey)
+
+	for _, file := range []string{
+		outside,
+		filepath.Join(base, "..", "outside"),
+	} {
+		_, err = ParseAsWithOptions[config](Options{
+			FileBaseDir: base,
+			Environment: map[string]string{"SECRET_KEY": file},
+		})
+		isErrorWithMessage(t, err, fmt.Sprintf("env: could not load content of file %q from variable SECRET_KEY: file is outside of %q", file, base))
+	}
+
+	if runtime.GOOS != "windows" {
+		link := filepath.Join(base, "link")
+		isNoErr(t, os.Symlink(outside, link))
+		_, err = ParseAsWithOptions[config](Options{
+			FileBaseDir: base,
+			Environment: map[string]string{"SECRET_KEY": link},
+		})
+		isErrorWithMessage(t, err, fmt.Sprintf("env: could not load content of file %q from variable SECRET_KEY: file is outside of %q", link, base))
+	}
+}
+
+func TestFileSystem(t *testing.T) {
+	type config struct {
+		SecretKey string `env:"SECRET_KEY,file"`
+		Password  string `env:"PASSWORD,keyFile"`
+	}
+
+	fsys := fstest.MapFS{
+		"run/secrets/key":      {Data: []byte("secret\n")},
+		"run/secrets/password": {Data: []byte("hunter2")},
+		"etc/passwd":           {Data: []byte("root")},
+	}
+
+	cfg, err := ParseAsWithOptions[config](Options{
+		FileSystem:              fsys,
+		FileBaseDir:             "run/secrets",
+		FileTrimTrailingNewline: true,
+		Environment: map[string]string{
+			"SECRET_KEY":    "run/secrets/key",
+			"PASSWORD_FILE": "run/secrets/password",
+		},
+	})
+	isNoErr(t, err)
+	isEqual(t, "secret", cfg.SecretKey)
+	isEqual(t, "hunter2", cfg.Password)
+
+	_, err = ParseAsWithOptions[config](Options{
+		FileSystem:  fsys,
+		FileBaseDir: "run/secrets",
+		Environment: map[string]string{"SECRET_KEY": "run/secrets/../../etc/passwd"},
+	})
+	isErrorWithMessage(t, err, `env: could not load content of file "run/secrets/../../etc/passwd" from variable SECRET_KEY: file is outside of "run/secrets"`)
+
+	_, err = ParseAsWithOptions[config](Options{
+		FileSystem:  fsys,
+		Environment: map[string]string{"SECRET_KEY": "nope"},
+	})
+	isTrue(t, errors.Is(err, LoadFileContentError{}))
+}
+
 func TestCustomSliceType(t *testing.T) {
 	type customslice []byte
 
@@ -1726,6 +2170,89 @@ func TestNoEnvKey(t *testing.T) {
 	isEqual(t, "", cfg.bar)
 }
 
+func TestUseFieldNameAsPrefix(t *testing.T) {
+	type DBConfig struct {
+		Host string
+		Port int
+	}
+	type Base struct {
+		Name string
+	}
+	type Config struct {
+		Base
+		Database     DBConfig
+		ReadReplica  *DBConfig
+		Cache        DBConfig `envPrefix:"REDIS_"`
+		Flat         DBConfig `envPrefix:""`
+		HTTPSettings struct {
+			Port int
+		}
+		Workers []struct {
+			Queue string
+		}
+		Shards []DBConfig
+	}
+
+	env := map[string]string{
+		"APP_NAME":                "app",
+		"APP_DATABASE_HOST":       "db",
+		"APP_DATABASE_PORT":       "5432",
+		"APP_READ_REPLICA_HOST":   "replica",
+		"APP_REDIS_HOST":          "redis",
+		"APP_HOST":                "flat",
+		"APP_HTTP_SETTINGS_PORT":  "8080",
+		"APP_WORKERS_0_QUEUE":     "default",
+		"APP_DATABASE__HOST":      "db2",
+		"APP_READ_REPLICA__HOST":  "replica2",
+		"APP_HTTP_SETTINGS__PORT": "8081",
+		"APP_WORKERS__0__QUEUE":   "other",
+		"APP_SHARDS_0_HOST":       "shard",
+		"APP_SHARDS__0__HOST":     "shard2",
+		"APP_SHARDS__0__PORT":     "1",
+		"APP_SHARDS__1_HOST":      "ignored",
+	}
+
+	cfg := Config{ReadReplica: &DBConfig{}}
+	isNoErr(t, ParseWithOptions(&cfg, Options{
+		Prefix:                "APP_",
+		Environment:           env,
+		UseFieldNameByDefault: true,
+		UseFieldNameAsPrefix:  true,
+	}))
+	isEqual(t, "app", cfg.Name)
+	isEqual(t, DBConfig{Host: "db", Port: 5432}, cfg.Database)
+	isEqual(t, DBConfig{Host: "replica"}, *cfg.ReadReplica)
+	isEqual(t, DBConfig{Host: "redis"}, cfg.Cache)
+	isEqual(t, DBConfig{Host: "flat"}, cfg.Flat)
+	isEqual(t, 8080, cfg.HTTPSettings.Port)
+	isEqual(t, 1, len(cfg.Workers))
+	isEqual(t, "default", cfg.Workers[0].Queue)
+	isEqual(t, []DBConfig{{Host: "shard"}}, cfg.Shards)
+
+	cfg = Config{ReadReplica: &DBConfig{}}
+	isNoErr(t, ParseWithOptions(&cfg, Options{
+		Prefix:                "APP_",
+		Environment:           env,
+		UseFieldNameByDefault: true,
+		UseFieldNameAsPrefix:  true,
+		PrefixSeparator:       "__",
+	}))
+	isEqual(t, DBConfig{Host: "db2"}, cfg.Database)
+	isEqual(t, DBConfig{Host: "replica2"}, *cfg.ReadReplica)
+	isEqual(t, 8081, cfg.HTTPSettings.Port)
+	isEqual(t, "other", cfg.Workers[0].Queue)
+	isEqual(t, []DBConfig{{Host: "shard2", Port: 1}}, cfg.Shards)
+
+	cfg = Config{ReadReplica: &DBConfig{}}
+	isNoErr(t, ParseWithOptions(&cfg, Options{
+		Prefix:                "APP_",
+		Environment:           env,
+		UseFieldNameByDefault: true,
+	}))
+	isEqual(t, DBConfig{Host: "flat"}, cfg.Database)
+	isEqual(t, DBConfig{Host: "flat"}, *cfg.ReadReplica)
+}
+
 func TestToEnv(t *testing.T) {
 	for in, out := range map[string]string{
 		"Foo":          "FOO",
@@ -1746,6 +2273,175 @@ func TestToEnv(t *testing.T) {
 	}
 }
 
+func TestKeyMatcher(t *testing.T) {
+	type Config struct {
+		Host    string `env:"DB_HOST"`
+		Port    int    `env:"DB_PORT" envDefault:"5432"`
+		Replica struct {
+			Host string `env:"HOST"`
+		} `envPrefix:"DB_REPLICA_"`
+		Users []struct {
+			Name string `env:"NAME"`
+		} `envPrefix:"USERS"`
+		Home string `env:"HOME_DIR,expand"`
+	}
+
+	env := map[string]string{
+		"app.db.host":         "db.local",
+		"APP-DB-PORT":         "5433",
+		"app_db_replica_host": "replica.local",
+		"app.users.0.name":    "carlos",
+		"app.users.1.name":    "becker",
+		"app.home-dir":        "/home/${APP_USERS_0_NAME}",
+	}
+
+	cfg, err := ParseAsWithOptions[Config](Options{
+		Prefix:      "APP_",
+		Environment: env,
+		KeyMatcher:  RelaxedKeyMatcher,
+	})
+	isNoErr(t, err)
+	isEqual(t, "db.local", cfg.Host)
+	isEqual(t, 5433, cfg.Port)
+	isEqual(t, "replica.local", cfg.Replica.Host)
+	isEqual(t, 2, len(cfg.Users))
+	isEqual(t, "carlos", cfg.Users[0].Name)
+	isEqual(t, "becker", cfg.Users[1].Name)
+	isEqual(t, "/home/carlos", cfg.Home)
+
+	cfg, err = ParseAsWithOptions[Config](Options{
+		Prefix:      "APP_",
+		Environment: env,
+	})
+	isNoErr(t, err)
+	isEqual(t, "", cfg.Host)
+	isEqual(t, 5432, cfg.Port)
+	isEqual(t, 0, len(cfg.Users))
+}
+
+func TestFieldParamsJSON(t *testing.T) {
+	type Config struct {
+		Level string `env:"LEVEL" envDefault.prod:"warn" envEnum:"debug,warn"`
+		Debug bool   `env:"DEBUG,lenientBool"`
+	}
+
+	fields, err := GetFieldsWithOptions(&Config{}, Options{BoolValues: map[string]bool{"on": true}})
+	isNoErr(t, err)
+
+	var params map[string]interface{}
+	b, err := json.Marshal(fields[0].FieldParams)
+	isNoErr(t, err)
+	isNoErr(t, json.Unmarshal(b, &params))
+	isEqual(t, "LEVEL", params["Key"])
+	isEqual(t, map[string]interface{}{"prod": "warn"}, params["ProfileDefaults"])
+	isEqual(t, []interface{}{"debug", "warn"}, params["Enum"])
+	_, ok := params["BoolValues"]
+	isFalse(t, ok)
+
+	var field map[string]interface{}
+	b, err = json.Marshal(fields[1])
+	isNoErr(t, err)
+	isNoErr(t, json.Unmarshal(b, &field))
+	isEqual(t, "DEBUG", field["Key"])
+	isEqual(t, "Debug", field["Path"])
+	isEqual(t, `env:"DEBUG,lenientBool"`, field["Tag"])
+	isEqual(t, true, field["LenientBool"])
+	isEqual(t, map[string]interface{}{"on": true}, field["BoolValues"])
+}
+
+func TestKeyMatcherUnset(t *testing.T) {
+	type Config struct {
+		Password string `env:"DB_PASSWORD,unset"`
+	}
+
+	t.Setenv("app_db_password", "s3cr3t")
+	cfg, err := ParseAsWithOptions[Config](Options{
+		Prefix:     "APP_",
+		KeyMatcher: RelaxedKeyMatcher,
+	})
+	isNoErr(t, err)
+	isEqual(t, "s3cr3t", cfg.Password)
+	_, exists := os.LookupEnv("app_db_password")
+	isFalse(t, exists)
+}
+
+func TestKeyMatcherExactMatchWins(t *testing.T) {
+	type Config struct {
+		Host string `env:"HOST"`
+		Port string `env:"PORT"`
+	}
+
+	cfg, err := ParseAsWithOptions[Config](Options{
+		Environment: map[string]string{
+			"host": "lower",
+			"HOST": "upper",
+			"port": "lower",
+			"Port": "title",
+		},
+		KeyMatcher: RelaxedKeyMatcher,
+	})
+	isNoErr(t, err)
+	isEqual(t, "upper", cfg.Host)
+	isEqual(t, "title", cfg.Port)
+}
+
+func TestKeyMatcherDirSource(t *testing.T) {
+	type Config struct {
+		Password string `env:"DB_PASSWORD,required"`
+	}
+
+	dir := t.TempDir()
+	isNoErr(t, os.WriteFile(filepath.Join(dir, "db-password"), []byte("hunter2"), 0o600))
+
+	cfg, err := ParseAsWithOptions[Config](Options{
+		Environment: map[string]string{},
+		Dirs:        []DirSource{{Path: dir}},
+		KeyMatcher:  RelaxedKeyMatcher,
+	})
+	isNoErr(t, err)
+	isEqual(t, "hunter2", cfg.Password)
+}
+
+func TestNameMapper(t *testing.T) {
+	type Config struct {
+		HTTPServerURL string
+		IPv6Addr      string
+		Port          int `env:"PORT"`
+	}
+
+	cfg, err := ParseAsWithOptions[Config](Options{
+		UseFieldNameByDefault: true,
+		NameMapper:            strings.ToLower,
+		Environment: map[string]string{
+			"httpserverurl": "http://localhost",
+			"ipv6addr":      "::1",
+			"PORT":          "8080",
+		},
+	})
+	isNoErr(t, err)
+	isEqual(t, Config{HTTPServerURL: "http://localhost", IPv6Addr: "::1", Port: 8080}, cfg)
+
+	cfg, err = ParseAsWithOptions[Config](Options{
+		NameMapper:  strings.ToLower,
+		Environment: map[string]string{"httpserverurl": "http://localhost"},
+	})
+	isNoErr(t, err)
+	isEqual(t, "", cfg.HTTPServerURL)
+}
+
+func TestRelaxedKeyMatcher(t *testing.T) {
+	for in, out := range map[string]string{
+		"FOO_BAR":     "FOO_BAR",
+		"foo.bar":     "FOO_BAR",
+		"foo-bar":     "FOO_BAR",
+		"Foo.Bar-Baz": "FOO_BAR_BAZ",
+	} {
+		t.Run(in, func(t *testing.T) {
+			isEqual(t, out, RelaxedKeyMatcher(in))
+		})
+	}
+}
+
 func TestErrorIs(t *testing.T) {
 	err := newAggregateError(newParseError(reflect.StructField{}, nil))
 	t.Run("is", func(t *testing.T) {
@@ -1756,6 +2452,191 @@ func TestErrorIs(t *testing.T) {
 	})
 }
 
+func TestErrorFieldPathAndKey(t *testing.T) {
+	type TLS struct {
+		CertFile string `env:"CERT_FILE,required"`
+		Port     int    `env:"PORT"`
+		Token    int    `env:"TOKEN,sensitive"`
+	}
+	type Server struct {
+		TLS *TLS `envPrefix:"TLS_"`
+	}
+	type Worker struct {
+		Queue int `env:"QUEUE"`
+	}
+	type Config struct {
+		Server  Server   `envPrefix:"SERVER_"`
+		Workers []Worker `envPrefix:"WORKERS"`
+		Mode    string   `env:"MODE,nope"`
+	}
+
+	cfg := Config{Server: Server{TLS: &TLS{}}}
+	err := ParseWithOptions(&cfg, Options{
+		Prefix: "APP_",
+		Environment: map[string]string{
+			"APP_SERVER_TLS_PORT":  "https",
+			"APP_SERVER_TLS_TOKEN": "hunter2",
+			"APP_WORKERS_0_QUEUE":  "1",
+			"APP_WORKERS_1_QUEUE":  "nope",
+		},
+	})
+
+	var agg AggregateError
+	isTrue(t, errors.As(err, &agg))
+	isEqual(t, 5, len(agg.Errors))
+
+	isEqual(t, VarIsNotSetError{Key: "APP_SERVER_TLS_CERT_FILE", Path: "Server.TLS.CertFile"}, agg.Errors[0])
+
+	perr := agg.Errors[1].(ParseError)
+	isEqual(t, "Server.TLS.Port", perr.Path)
+	isEqual(t, "APP_SERVER_TLS_PORT", perr.Key)
+	isEqual(t, "https", perr.Value)
+	isTrue(t, errors.Is(perr, strconv.ErrSyntax))
+
+	perr = agg.Errors[2].(ParseError)
+	isEqual(t, "Server.TLS.Token", perr.Path)
+	isEqual(t, "", perr.Value)
+	isFalse(t, strings.Contains(perr.Error(), "hunter2"))
+	isTrue(t, errors.Is(perr, strconv.ErrSyntax))
+
+	perr = agg.Errors[3].(ParseError)
+	isEqual(t, "Workers[1].Queue", perr.Path)
+	isEqual(t, "APP_WORKERS_1_QUEUE", perr.Key)
+
+	isEqual(t, NoSupportedTagOptionError{Tag: "nope", Path: "Mode", Key: "APP_MODE"}, agg.Errors[4])
+
+	var ferr FieldError
+	isTrue(t, errors.As(err, &ferr))
+	isEqual(t, "Server.TLS.CertFile", ferr.FieldPath())
+	isEqual(t, "APP_SERVER_TLS_CERT_FILE", ferr.EnvKey())
+}
+
+func TestAggregateErrorJSON(t *testing.T) {
+	type Config struct {
+		Host string `env:"HOST,required"`
+		Port int    `env:"PORT"`
+	}
+
+	err := ParseWithOptions(&Config{}, Options{
+		Environment: map[string]string{"PORT": "http"},
+	})
+	b, jerr := json.Marshal(err)
+	isNoErr(t, jerr)
+	isEqual(t, `{"errors":[`+
+		`{"type":"VarIsNotSetError","message":"required environment variable \"HOST\" is not set","path":"Host","key":"HOST"},`+
+		`{"type":"ParseError","message":"parse error on field \"Port\" of type \"int\": strconv.ParseInt: parsing \"http\": invalid syntax","path":"Port","key":"PORT","value":"http"}`+
+		`]}`, string(b))
+
+	type Secrets struct {
+		Token  int               `env:"TOKEN,sensitive"`
+		Keys   []int             `env:"KEYS,sensitive"`
+		Wait   time.Duration     `env:"WAIT,sensitive"`
+		Labels map[string]string `env:"LABELS,sensitive"`
+	}
+
+	err = ParseWithOptions(&Secrets{}, Options{
+		Environment: map[string]string{
+			"TOKEN":  "hunter2",
+			"KEYS":   "1,hunter2",
+			"WAIT":   "hunter2",
+			"LABELS": "hunter2",
+		},
+	})
+	b, jerr = json.Marshal(err)
+	isNoErr(t, jerr)
+	isFalse(t, strings.Contains(err.Error(), "hunter2"))
+	isEqual(t, `{"errors":[`+
+		`{"type":"ParseError","message":"parse error on field \"Token\" of type \"int\": strconv.ParseInt: parsing \"[REDACTED]\": invalid syntax","path":"Token","key":"TOKEN"},`+
+		`{"type":"ParseError","message":"parse error on field \"Keys\" of type \"[]int\": strconv.ParseInt: parsing \"[REDACTED]\": invalid syntax","path":"Keys","key":"KEYS"},`+
+		`{"type":"ParseError","message":"parse error on field \"Wait\" of type \"time.Duration\": invalid value \"[REDACTED]\"","path":"Wait","key":"WAIT"},`+
+		`{"type":"ParseError","message":"parse error on field \"Labels\" of type \"map[string]string\": invalid value \"[REDACTED]\"","path":"Labels","key":"LABELS"}`+
+		`]}`, string(b))
+
+	b, jerr = json.Marshal(newAggregateError(NotStructPtrError{}))
+	isNoErr(t, jerr)
+	isEqual(t, `{"errors":[{"type":"NotStructPtrError","message":"expected a pointer to a Struct"}]}`, string(b))
+}
+
+func TestProfileDefaults(t *testing.T) {
+	type Config struct {
+		LogLevel string `env:"LOG_LEVEL" envDefault:"info" envDefault.prod:"warn" envDefault.dev:"debug"`
+		Port     int    `env:"PORT" envDefault:"8080"`
+		Host     string `env:"HOST" envDefault.dev:"localhost"`
+	}
+
+	for name, tt := range map[string]struct {
+		opts     Options
+		expected Config
+	}{
+		"no profile": {
+			opts:     Options{},
+			expected: Config{LogLevel: "info", Port: 8080},
+		},
+		"prod": {
+			opts:     Options{Profile: "prod"},
+			expected: Config{LogLevel: "warn", Port: 8080},
+		},
+		"dev": {
+			opts:     Options{Profile: "dev"},
+			expected: Config{LogLevel: "debug", Port: 8080, Host: "localhost"},
+		},
+		"unknown": {
+			opts:     Options{Profile: "staging"},
+			expected: Config{LogLevel: "info", Port: 8080},
+		},
+		"from variable": {
+			opts: Options{
+				ProfileKey:  "APP_ENV",
+				Environment: map[string]string{"APP_ENV": "dev"},
+			},
+			expected: Config{LogLevel: "debug", Port: 8080, Host: "localhost"},
+		},
+		"explicit profile wins": {
+			opts: Options{
+				Profile:     "prod",
+				ProfileKey:  "APP_ENV",
+				Environment: map[string]string{"APP_ENV": "dev"},
+			},
+			expected: Config{LogLevel: "warn", Port: 8080},
+		},
+		"variable wins": {
+			opts: Options{
+				Profile:     "prod",
+				Environment: map[string]string{"LOG_LEVEL": "error"},
+			},
+			expected: Config{LogLevel: "error", Port: 8080},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if tt.opts.Environment == nil {
+				tt.opts.Environment = map[string]string{}
+			}
+			cfg, err := ParseAsWithOptions[Config](tt.opts)
+			isNoErr(t, err)
+			isEqual(t, tt.expected, cfg)
+		})
+	}
+}
+
+func TestProfileDefaultsFieldParams(t *testing.T) {
+	type Config struct {
+		LogLevel string `env:"LOG_LEVEL" envDefault:"info" envDefault.prod:"warn" json:"log" envDefault.dev:"debug \"quoted\""`
+		Host     string `env:"HOST" envDefault.:"ignored"`
+	}
+
+	params, err := GetFieldParamsWithOptions(&Config{}, Options{Profile: "dev"})
+	isNoErr(t, err)
+	isEqual(t, 2, len(params))
+	isEqual(t, "LOG_LEVEL", params[0].Key)
+	isEqual(t, `debug "quoted"`, params[0].DefaultValue)
+	isTrue(t, params[0].HasDefaultValue)
+	isEqual(t, map[string]string{"prod": "warn", "dev": `debug "quoted"`}, params[0].ProfileDefaults())
+	isEqual(t, FieldParams{OwnKey: "HOST", Key: "HOST"}, params[1])
+	// FieldParams must stay comparable.
+	isTrue(t, params[1] == FieldParams{OwnKey: "HOST", Key: "HOST"})
+	isEqual(t, map[string]string(nil), params[1].ProfileDefaults())
+}
+
 type FieldParamsConfig struct {
 	Simple         []string `env:"SIMPLE"`
 	WithoutEnv     string
@@ -1810,6 +2691,28 @@ func TestGetFieldParamsWithPrefix(t *testing.T) {
 	isTrue(t, areEqual(params, expectedParams))
 }
 
+func TestGetFields(t *testing.T) {
+	var config FieldParamsConfig
+	fields, err := GetFieldsWithOptions(&config, Options{Prefix: "FOO_"})
+	isNoErr(t, err)
+	isEqual(t, 8, len(fields))
+	isEqual(t, Field{
+		FieldParams: FieldParams{OwnKey: "SIMPLE", Key: "FOO_SIMPLE"},
+		Path:        "Simple",
+		Type:        reflect.TypeOf([]string{}),
+		Tag:         `env:"SIMPLE"`,
+	}, fields[0])
+	isEqual(t, Field{
+		FieldParams: FieldParams{OwnKey: "SIMPLE", Key: "FOO_NESTED_SIMPLE"},
+		Path:        "NestedConfig.Simple",
+		Type:        reflect.TypeOf([]string{}),
+		Tag:         `env:"SIMPLE"`,
+	}, fields[7])
+
+	_, err = GetFields(config)
+	isTrue(t, errors.Is(err, NotStructPtrError{}))
+}
+
 func TestGetFieldParamsError(t *testing.T) {
 	var config FieldParamsConfig
 
@@ -2417,3 +3320,112 @@ func TestEnvBleed(t *testing.T) {
 		isEqual(t, "", cfg.Foo)
 	})
 }
+
+func TestEnum(t *testing.T) {
+	type Config struct {
+		Level string         `env:"LEVEL" envDefault:"info" envEnum:"debug,info,warn"`
+		Token Secret[string] `env:"TOKEN" envEnum:"a,b"`
+	}
+
+	cfg, err := ParseAsWithOptions[Config](Options{Environment: map[string]string{"LEVEL": "debug"}})
+	isNoErr(t, err)
+	isEqual(t, "debug", cfg.Level)
+
+	cfg, err = ParseAsWithOptions[Config](Options{Environment: map[string]string{}})
+	isNoErr(t, err)
+	isEqual(t, "info", cfg.Level)
+
+	// envEnum is only descriptive.
+	cfg, err = ParseAsWithOptions[Config](Options{Environment: map[string]string{"LEVEL": "trace", "TOKEN": "c"}})
+	isNoErr(t, err)
+	isEqual(t, "trace", cfg.Level)
+	isEqual(t, "c", cfg.Token.Reveal())
+
+	params, err := GetFieldParams(&Config{})
+	isNoErr(t, err)
+	isEqual(t, []string{"debug", "info", "warn"}, params[0].Enum())
+	isEqual(t, []string{"a", "b"}, params[1].Enum())
+
+	params, err = GetFieldParams(&struct {
+		Host string `env:"HOST"`
+	}{})
+	isNoErr(t, err)
+	isEqual(t, []string(nil), params[0].Enum())
+}
+
+func TestDuplicateKeys(t *testing.T) {
+	type Database struct {
+		Host string `env:"HOST"`
+	}
+
+	type Embedded struct {
+		Port int `env:"PORT"`
+	}
+
+	type Config struct {
+		Embedded
+		Host     string `env:"HOST"`
+		Port     int    `env:"PORT"`
+		Database Database
+		Cache    Database `envPrefix:"CACHE_"`
+		Lower    string   `env:"cache_host"`
+	}
+
+	environment := map[string]string{"HOST": "localhost", "PORT": "8080"}
+
+	t.Run("default", func(t *testing.T) {
+		var dups []DuplicateKeyError
+		cfg, err := ParseAsWithOptions[Config](Options{
+			Environment: environment,
+			OnDuplicateKey: func(err DuplicateKeyError) {
+				dups = append(dups, err)
+			},
+		})
+		isNoErr(t, err)
+		isEqual(t, "localhost", cfg.Database.Host)
+		isEqual(t, 8080, cfg.Embedded.Port)
+		isEqual(t, []DuplicateKeyError{
+			{Key: "PORT", Paths: [2]string{"Embedded.Port", "Port"}},
+			{Key: "HOST", Paths: [2]string{"Host", "Database.Host"}},
+		}, dups)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		_, err := ParseAsWithOptions[Config](Options{
+			Environment:          environment,
+			ErrorOnDuplicateKeys: true,
+		})
+		isErrorWithMessage(t, err, `env: environment variable "PORT" is used by both Embedded.Port and Port; environment variable "HOST" is used by both Host and Database.Host`)
+
+		var dupErr DuplicateKeyError
+		isTrue(t, errors.As(err, &dupErr))
+		isEqual(t, "Port", dupErr.FieldPath())
+		isEqual(t, "PORT", dupErr.EnvKey())
+	})
+
+	t.Run("key matcher", func(t *testing.T) {
+		_, err := ParseAsWithOptions[Config](Options{
+			Environment:          environment,
+			ErrorOnDuplicateKeys: true,
+			KeyMatcher:           RelaxedKeyMatcher,
+		})
+		isErrorWithMessage(t, err, `env: environment variable "PORT" is used by both Embedded.Port and Port; environment variable "HOST" is used by both Host and Database.Host; environment variable "cache_host" is used by both Cache.Host and Lower`)
+	})
+
+	t.Run("field params", func(t *testing.T) {
+		_, err := GetFieldParamsWithOptions(&Config{}, Options{ErrorOnDuplicateKeys: true})
+		isErrorWithMessage(t, err, `env: environment variable "PORT" is used by both Embedded.Port and Port; environment variable "HOST" is used by both Host and Database.Host`)
+	})
+
+	t.Run("initIfSet", func(t *testing.T) {
+		type Config struct {
+			Database *Database `env:",initIfSet"`
+		}
+		cfg, err := ParseAsWithOptions[Config](Options{
+			Environment:          environment,
+			ErrorOnDuplicateKeys: true,
+		})
+		isNoErr(t, err)
+		isEqual(t, "localhost", cfg.Database.Host)
+	})
+}
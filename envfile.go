@@ -0,0 +1,194 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// EnvFileFormat is the format of the files written by WriteEnvFile.
+type EnvFileFormat int
+
+const (
+	// DotEnv is the format of `.env` files, as read by most dotenv libraries.
+	// Values are quoted when needed.
+	DotEnv EnvFileFormat = iota
+
+	// SystemdEnvironmentFile is the format of the files read by the
+	// `EnvironmentFile` setting of systemd units. Values are quoted when
+	// needed, and multi-line values span several lines, as systemd does not
+	// expand `\n` escapes.
+	SystemdEnvironmentFile
+
+	// DockerEnvFile is the format of the files read by `docker run
+	// --env-file`. Values are never quoted, as Docker would keep the quotes.
+	DockerEnvFile
+)
+
+// WriteEnvFile writes an example environment file for v to w, e.g. a
+// `.env.example` file.
+//
+// Each variable is written on its own line, with its default value, preceded
+// by comments with its `envDescription` tag, whether it is required or
+// sensitive, and the values listed in its `envEnum` tag. Optional
+// variables without a default value are commented out. Variables sharing the
+// same `envPrefix` are grouped in sections, and so are the variables of each
+// variant registered with RegisterVariant, which are commented out too unless
+// they have a default value.
+func WriteEnvFile(w io.Writer, v interface{}, opts Options, format EnvFileFormat) error {
+	fields, err := schemaFields(v, opts)
+	if err != nil {
+		return err
+	}
+
+	keyFileSuffix := opts.KeyFileSuffix
+	if keyFileSuffix == "" {
+		keyFileSuffix = defaultOptions().KeyFileSuffix
+	}
+
+	var sections []envFileSection
+	index := map[string]int{}
+	for _, field := range fields {
+		prefix := strings.TrimSuffix(field.Key, field.OwnKey)
+		i, ok := index[prefix]
+		if !ok {
+			i = len(sections)
+			index[prefix] = i
+			sections = append(sections, envFileSection{
+				prefix:     prefix,
+				path:       parentPath(field.Path),
+				variantKey: field.variantKey,
+				variant:    field.variant,
+			})
+		}
+		sections[i].fields = append(sections[i].fields, field)
+	}
+
+	ew := &errWriter{w: w}
+	for i, section := range sections {
+		if i > 0 {
+			ew.printf("\n")
+		}
+		switch {
+		case section.variantKey != "":
+			ew.printf("# %s, when %s is %q (%s*)\n\n", section.path, section.variantKey, section.variant, section.prefix)
+		case section.prefix != opts.Prefix:
+			ew.printf("# %s (%s*)\n\n", section.path, section.prefix)
+		}
+		for j, field := range section.fields {
+			if j > 0 {
+				ew.printf("\n")
+			}
+			if field.Description != "" {
+				for _, line := range strings.Split(field.Description, "\n") {
+					ew.printf("# %s\n", line)
+				}
+			}
+			if attrs := envFileAttributes(field.Field, keyFileSuffix); len(attrs) > 0 {
+				ew.printf("# %s\n", strings.Join(attrs, ", "))
+			}
+
+			value, err := envFileValue(field.DefaultValue, format)
+			if err != nil {
+				return fmt.Errorf("env: %s: %w", field.Key, err)
+			}
+			if !field.HasDefaultValue && (field.variantKey != "" || !field.Required && !field.NotEmpty) {
+				ew.printf("# ")
+			}
+			ew.printf("%s=%s\n", field.Key, value)
+		}
+	}
+	return ew.err
+}
+
+type envFileSection struct {
+	prefix     string
+	path       string
+	variantKey string
+	variant    string
+	fields     []schemaField
+}
+
+func envFileAttributes(field Field, keyFileSuffix string) []string {
+	var attrs []string
+	if field.Required && !field.HasDefaultValue {
+		attrs = append(attrs, "required")
+	}
+	if field.NotEmpty {
+		attrs = append(attrs, "must not be empty")
+	}
+	if field.Sensitive {
+		attrs = append(attrs, "sensitive")
+	}
+	if field.LoadFile {
+		attrs = append(attrs, "path to a file")
+	}
+	if field.KeyFile {
+		attrs = append(attrs, fmt.Sprintf("can be read from the file in %s%s", field.Key, keyFileSuffix))
+	}
+	if enum := field.Enum(); len(enum) > 0 {
+		attrs = append(attrs, "one of: "+strings.Join(enum, ", "))
+	}
+	return attrs
+}
+
+// envFileValue quotes value as needed by format.
+func envFileValue(value string, format EnvFileFormat) (string, error) {
+	if format == DockerEnvFile {
+		if strings.ContainsAny(value, "\r\n") {
+			return "", errors.New("docker env files do not support multi-line values")
+		}
+		return value, nil
+	}
+
+	if !strings.ContainsAny(value, " \t\r\n#'\"\\$`") {
+		return value, nil
+	}
+	if !strings.ContainsAny(value, "'\r\n") {
+		return "'" + value + "'", nil
+	}
+
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, r := range value {
+		switch r {
+		case '"', '\\', '$', '`':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case '\n', '\r':
+			if format == SystemdEnvironmentFile {
+				// systemd keeps the line breaks of double-quoted values.
+				sb.WriteRune(r)
+			} else if r == '\n' {
+				sb.WriteString(`\n`)
+			} else {
+				sb.WriteString(`\r`)
+			}
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String(), nil
+}
+
+// parentPath returns the path of the struct holding the field at path.
+func parentPath(path string) string {
+	if i := strings.LastIndexByte(path, '.'); i >= 0 {
+		return path[:i]
+	}
+	return ""
+}
+
+// errWriter writes to w until an error occurs.
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (ew *errWriter) printf(format string, args ...interface{}) {
+	if ew.err == nil {
+		_, ew.err = fmt.Fprintf(ew.w, format, args...)
+	}
+}
@@ -0,0 +1,137 @@
+package env
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteEnvFile(t *testing.T) {
+	type Database struct {
+		Host     string         `env:"HOST" envDefault:"localhost"`
+		Password Secret[string] `env:"PASSWORD,required,keyFile"`
+	}
+
+	type Config struct {
+		Host     string   `env:"HOST,required" envDescription:"Address to listen on."`
+		Port     int      `env:"PORT" envDefault:"8080"`
+		Level    string   `env:"LEVEL" envDefault:"info" envEnum:"debug,info,warn"`
+		Greeting string   `env:"GREETING" envDefault:"hello world"`
+		Template string   `env:"TEMPLATE" envDefault:"it's ${USER}"`
+		Name     string   `env:"NAME,notEmpty"`
+		Cert     string   `env:"CERT,file" envDescription:"TLS certificate.\nPEM encoded."`
+		Database Database `envPrefix:"DB_"`
+		Debug    bool     `env:"DEBUG"`
+	}
+
+	t.Run("dotenv", func(t *testing.T) {
+		var buf bytes.Buffer
+		isNoErr(t, WriteEnvFile(&buf, &Config{}, Options{Prefix: "APP_"}, DotEnv))
+		isEqual(t, `# Address to listen on.
+# required
+APP_HOST=
+
+APP_PORT=8080
+
+# one of: debug, info, warn
+APP_LEVEL=info
+
+APP_GREETING='hello world'
+
+APP_TEMPLATE="it's \${USER}"
+
+# must not be empty
+APP_NAME=
+
+# TLS certificate.
+# PEM encoded.
+# path to a file
+# APP_CERT=
+
+# APP_DEBUG=
+
+# Database (APP_DB_*)
+
+APP_DB_HOST=localhost
+
+# required, sensitive, can be read from the file in APP_DB_PASSWORD_FILE
+APP_DB_PASSWORD=
+`, buf.String())
+	})
+
+	t.Run("systemd", func(t *testing.T) {
+		var buf bytes.Buffer
+		isNoErr(t, WriteEnvFile(&buf, &Config{}, Options{}, SystemdEnvironmentFile))
+		isTrue(t, bytes.Contains(buf.Bytes(), []byte("\nGREETING='hello world'\n")))
+	})
+
+	t.Run("docker", func(t *testing.T) {
+		var buf bytes.Buffer
+		isNoErr(t, WriteEnvFile(&buf, &Config{}, Options{}, DockerEnvFile))
+		isTrue(t, bytes.Contains(buf.Bytes(), []byte("\nGREETING=hello world\n")))
+		isTrue(t, bytes.Contains(buf.Bytes(), []byte("\nTEMPLATE=it's ${USER}\n")))
+	})
+
+	t.Run("docker multi-line", func(t *testing.T) {
+		type Config struct {
+			Motd string `env:"MOTD" envDefault:"hello\nworld"`
+		}
+		var buf bytes.Buffer
+		err := WriteEnvFile(&buf, &Config{}, Options{}, DockerEnvFile)
+		isErrorWithMessage(t, err, "env: MOTD: docker env files do not support multi-line values")
+	})
+
+	t.Run("multi-line", func(t *testing.T) {
+		type Config struct {
+			Motd string `env:"MOTD" envDefault:"hello\nworld"`
+		}
+		var buf bytes.Buffer
+		isNoErr(t, WriteEnvFile(&buf, &Config{}, Options{}, DotEnv))
+		isEqual(t, "MOTD=\"hello\\nworld\"\n", buf.String())
+	})
+
+	t.Run("systemd multi-line", func(t *testing.T) {
+		type Config struct {
+			Motd string `env:"MOTD" envDefault:"hello\n\"world\""`
+		}
+		var buf bytes.Buffer
+		isNoErr(t, WriteEnvFile(&buf, &Config{}, Options{}, SystemdEnvironmentFile))
+		isEqual(t, "MOTD=\"hello\n\\\"world\\\"\"\n", buf.String())
+	})
+
+	t.Run("variants", func(t *testing.T) {
+		type Config struct {
+			Storage storage `env:"STORAGE" envPrefix:"STORAGE_" envDefault:"s3"`
+			Debug   bool    `env:"DEBUG"`
+		}
+		opts := Options{}
+		RegisterVariant[storage, s3Storage](&opts, "s3")
+		RegisterVariant[storage, gcsStorage](&opts, "gcs")
+
+		var buf bytes.Buffer
+		isNoErr(t, WriteEnvFile(&buf, &Config{}, opts, DotEnv))
+		isEqual(t, `STORAGE=s3
+
+# DEBUG=
+
+# Storage, when STORAGE is "gcs" (STORAGE_GCS_*)
+
+# required
+# STORAGE_GCS_BUCKET=
+
+# Storage, when STORAGE is "s3" (STORAGE_S3_*)
+
+# required
+# STORAGE_S3_BUCKET=
+
+STORAGE_S3_REGION=us-east-1
+`, buf.String())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		type Config struct {
+			Host string `env:"HOST,nope"`
+		}
+		err := WriteEnvFile(&bytes.Buffer{}, &Config{}, Options{}, DotEnv)
+		isErrorWithMessage(t, err, `env: tag option "nope" not supported`)
+	})
+}
@@ -0,0 +1,14 @@
+// Command envlint checks the struct tags used by the env package. It is meant
+// to be run with go vet:
+//
+//	go vet -vettool=$(which envlint) ./...
+package main
+
+import (
+	"github.com/caarlos0/env/v11/envlint"
+	"golang.org/x/tools/go/analysis/unitchecker"
+)
+
+func main() {
+	unitchecker.Main(envlint.Analyzer)
+}
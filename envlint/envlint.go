@@ -0,0 +1,482 @@
+// Package envlint defines an analyzer that checks the struct tags used by the
+// github.com/caarlos0/env package, reporting mistakes that would otherwise
+// only show up at runtime, or not at all:
+//
+//   - unknown `env` tag options;
+//   - `envDefault` values that cannot be parsed into the field type, in the
+//     base of its `envBase` tag, if any;
+//   - invalid `envBase` tags;
+//   - env tags on unexported fields, which are ignored;
+//   - `required` fields with an `envDefault`, which are never missing;
+//   - fields resolving to the same key once prefixes are applied;
+//   - `envSeparator` on fields that are not slices, arrays or maps, or not
+//     listing one separator per level of nested slices, arrays and maps.
+//
+// It can be run with go vet:
+//
+//	go install github.com/caarlos0/env/v11/envlint/cmd/envlint@latest
+//	go vet -vettool=$(which envlint) ./...
+package envlint
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// Analyzer checks the struct tags used by the env package.
+var Analyzer = &analysis.Analyzer{ //nolint:gochecknoglobals
+	Name:     "envlint",
+	Doc:      "check the struct tags used by github.com/caarlos0/env",
+	URL:      "https://pkg.go.dev/github.com/caarlos0/env/v11/envlint",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+const envPkgPath = "github.com/caarlos0/env/v11"
+
+// tagOptions are the options supported by the `env` tag, as handled by
+// parseFieldParams in the env package (checked by TestTagOptions).
+var tagOptions = map[string]bool{ //nolint:gochecknoglobals
+	"":            true,
+	"file":        true,
+	"keyFile":     true,
+	"sensitive":   true,
+	"required":    true,
+	"unset":       true,
+	"notEmpty":    true,
+	"expand":      true,
+	"quoted":      true,
+	"trimSpace":   true,
+	"skipEmpty":   true,
+	"init":        true,
+	"initIfSet":   true,
+	"lenientBool": true,
+	"-":           true,
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	insp.Preorder([]ast.Node{(*ast.StructType)(nil)}, func(n ast.Node) {
+		st, ok := pass.TypesInfo.TypeOf(n.(*ast.StructType)).(*types.Struct)
+		if !ok {
+			return
+		}
+		for i := 0; i < st.NumFields(); i++ {
+			checkField(pass, st.Field(i), reflect.StructTag(st.Tag(i)))
+		}
+		checkDuplicates(pass, st)
+	})
+	return nil, nil
+}
+
+func checkField(pass *analysis.Pass, field *types.Var, tag reflect.StructTag) {
+	envTag, hasEnv := tag.Lookup("env")
+	defaultValue, hasDefault := tag.Lookup("envDefault")
+	separator, hasSeparator := tag.Lookup("envSeparator")
+	_, hasPrefix := tag.Lookup("envPrefix")
+	if !hasEnv && !hasDefault && !hasSeparator && !hasPrefix {
+		return
+	}
+
+	if !field.Exported() {
+		pass.Reportf(field.Pos(), "env tags on unexported field %s are ignored", field.Name())
+		return
+	}
+
+	key, options := parseEnvTag(envTag)
+	if key == "-" {
+		return
+	}
+
+	required := false
+	for _, option := range options {
+		if !tagOptions[option] {
+			pass.Reportf(field.Pos(), "unknown env tag option %q on field %s", option, field.Name())
+		}
+		if option == "required" {
+			required = true
+		}
+	}
+
+	if required && hasDefault {
+		pass.Reportf(field.Pos(), "field %s is required, but its envDefault makes it never missing", field.Name())
+	}
+
+	typ := valueType(field.Type())
+	if hasSeparator {
+		switch typ.Underlying().(type) {
+		case *types.Slice, *types.Array, *types.Map:
+		default:
+			pass.Reportf(field.Pos(), "envSeparator on field %s of type %s, which is not a slice, an array or a map", field.Name(), field.Type())
+		}
+	}
+
+	list, err := newListOptions(typ, separator, tag.Get("envKeyValSeparator"), options)
+	if err != nil {
+		pass.Reportf(field.Pos(), "%v on field %s of type %s", err, field.Name(), field.Type())
+		return
+	}
+
+	if base, ok := tag.Lookup("envBase"); ok {
+		b, err := strconv.Atoi(base)
+		if err != nil || b < 0 || b == 1 || b > 36 {
+			pass.Reportf(field.Pos(), "invalid envBase %q on field %s", base, field.Name())
+			return
+		}
+		list.base, list.hasBase = b, true
+		list.intBits = int(pass.TypesSizes.Sizeof(types.Typ[types.Int]) * 8)
+	}
+
+	if hasDefault && defaultValue != "" && !hasOption(options, "file") && !hasOption(options, "quoted") {
+		if err := checkValue(typ, defaultValue, list); err != nil {
+			pass.Reportf(field.Pos(), "invalid envDefault for field %s: %v", field.Name(), err)
+		}
+	}
+}
+
+func parseEnvTag(tag string) (string, []string) {
+	parts := strings.Split(tag, ",")
+	return parts[0], parts[1:]
+}
+
+func hasOption(options []string, option string) bool {
+	for _, o := range options {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+// valueType returns the type parsed into a field of type typ, looking through
+// pointers, env.Optional and env.Secret.
+func valueType(typ types.Type) types.Type {
+	for {
+		if ptr, ok := typ.Underlying().(*types.Pointer); ok {
+			typ = ptr.Elem()
+			continue
+		}
+		named, ok := typ.(*types.Named)
+		if !ok || named.Obj().Pkg() == nil || named.Obj().Pkg().Path() != envPkgPath {
+			return typ
+		}
+		if name := named.Obj().Name(); (name != "Optional" && name != "Secret") || named.TypeArgs().Len() != 1 {
+			return typ
+		}
+		typ = named.TypeArgs().At(0)
+	}
+}
+
+// listOptions are the tags and tag options used to parse values: the ones
+// splitting the values of slices, arrays and maps, with one separator per level
+// of nested collections, and the base of integers. Values with quoted items,
+// and lenient booleans, are not checked.
+type listOptions struct {
+	separators       []string
+	keyValSeparators []string
+	trimSpace        bool
+	skipEmpty        bool
+	base             int
+	hasBase          bool
+	intBits          int
+	lenientBool      bool
+}
+
+// newListOptions returns the listOptions of a field of type typ, as the env
+// package computes them.
+func newListOptions(typ types.Type, separator, keyValSeparator string, options []string) (listOptions, error) {
+	list := listOptions{
+		trimSpace:   hasOption(options, "trimSpace"),
+		skipEmpty:   hasOption(options, "skipEmpty"),
+		lenientBool: hasOption(options, "lenientBool"),
+	}
+
+	levels, mapLevels := 0, 0
+	for isCollection(typ) {
+		levels++
+		if _, ok := typ.Underlying().(*types.Map); ok {
+			mapLevels++
+		}
+		typ = typ.Underlying().(interface{ Elem() types.Type }).Elem()
+	}
+
+	switch {
+	case levels <= 1 && separator == "":
+		list.separators = []string{","}
+	case levels <= 1:
+		list.separators = []string{separator}
+	case utf8.RuneCountInString(separator) != levels:
+		return list, fmt.Errorf("envSeparator %q should have one separator per level", separator)
+	default:
+		list.separators = strings.Split(separator, "")
+	}
+
+	if keyValSeparator == "" {
+		keyValSeparator = ":"
+	}
+	if mapLevels > 1 && utf8.RuneCountInString(keyValSeparator) == mapLevels {
+		list.keyValSeparators = strings.Split(keyValSeparator, "")
+	} else {
+		for i := 0; i < mapLevels; i++ {
+			list.keyValSeparators = append(list.keyValSeparators, keyValSeparator)
+		}
+	}
+	return list, nil
+}
+
+// isCollection reports whether typ is a slice, an array or a map parsed by
+// splitting its value.
+func isCollection(typ types.Type) bool {
+	switch typ.Underlying().(type) {
+	case *types.Slice, *types.Array, *types.Map:
+		return !isTextUnmarshaler(typ)
+	}
+	return false
+}
+
+// split splits value around sep, honoring the trimSpace and skipEmpty tag
+// options.
+func (o listOptions) split(value, sep string) []string {
+	var parts []string
+	for _, part := range strings.Split(value, sep) {
+		if o.trimSpace {
+			part = strings.TrimSpace(part)
+		}
+		if o.skipEmpty && part == "" {
+			continue
+		}
+		parts = append(parts, part)
+	}
+	return parts
+}
+
+// checkValue reports whether value can be parsed into typ by the default
+// parsers of the env package. Types with custom parsers are not checked.
+func checkValue(typ types.Type, value string, list listOptions) error {
+	if isNamed(typ, "time", "Duration") {
+		_, err := time.ParseDuration(value)
+		return err
+	}
+	if isNamed(typ, "net/url", "URL") {
+		_, err := url.Parse(value)
+		return err
+	}
+	if isTextUnmarshaler(typ) {
+		return nil
+	}
+
+	switch u := typ.Underlying().(type) {
+	case *types.Basic:
+		if u.Kind() == types.Bool && list.lenientBool {
+			// Lenient booleans depend on Options.BoolValues.
+			return nil
+		}
+		if list.hasBase {
+			return checkInteger(typ, u, value, list)
+		}
+		return checkBasic(u, value)
+	case *types.Slice:
+		elem := u.Elem()
+		if ptr, ok := elem.(*types.Pointer); ok {
+			elem = ptr.Elem()
+		}
+		next := list
+		next.separators = list.separators[1:]
+		for _, part := range list.split(value, list.separators[0]) {
+			if err := checkValue(elem, part, next); err != nil {
+				return err
+			}
+		}
+	case *types.Array:
+		parts := list.split(value, list.separators[0])
+		if int64(len(parts)) != u.Len() {
+			return fmt.Errorf("expected %d items, got %d", u.Len(), len(parts))
+		}
+		next := list
+		next.separators = list.separators[1:]
+		for _, part := range parts {
+			if err := checkValue(valueType(u.Elem()), part, next); err != nil {
+				return err
+			}
+		}
+	case *types.Map:
+		next := list
+		next.separators, next.keyValSeparators = list.separators[1:], list.keyValSeparators[1:]
+		for _, part := range list.split(value, list.separators[0]) {
+			k, v, ok := strings.Cut(part, list.keyValSeparators[0])
+			if !ok {
+				return fmt.Errorf(`%q should be in "key%svalue" format`, part, list.keyValSeparators[0])
+			}
+			if list.trimSpace {
+				k, v = strings.TrimSpace(k), strings.TrimSpace(v)
+			}
+			if err := checkValue(u.Key(), k, next); err != nil {
+				return err
+			}
+			if err := checkValue(valueType(u.Elem()), v, next); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func checkBasic(typ *types.Basic, value string) error {
+	var err error
+	switch typ.Kind() {
+	case types.Bool:
+		_, err = strconv.ParseBool(value)
+	case types.Int, types.Int32:
+		_, err = strconv.ParseInt(value, 10, 32)
+	case types.Int8:
+		_, err = strconv.ParseInt(value, 10, 8)
+	case types.Int16:
+		_, err = strconv.ParseInt(value, 10, 16)
+	case types.Int64:
+		_, err = strconv.ParseInt(value, 10, 64)
+	case types.Uint, types.Uint32:
+		_, err = strconv.ParseUint(value, 10, 32)
+	case types.Uint8:
+		_, err = strconv.ParseUint(value, 10, 8)
+	case types.Uint16:
+		_, err = strconv.ParseUint(value, 10, 16)
+	case types.Uint64:
+		_, err = strconv.ParseUint(value, 10, 64)
+	case types.Float32:
+		_, err = strconv.ParseFloat(value, 32)
+	case types.Float64:
+		_, err = strconv.ParseFloat(value, 64)
+	}
+	return err
+}
+
+// checkInteger checks an integer parsed in the base of the `envBase` tag, using
+// the full width of int and uint.
+func checkInteger(typ types.Type, basic *types.Basic, value string, list listOptions) error {
+	if isNamed(typ, "io/fs", "FileMode") && list.base == 0 && !hasBasePrefix(value) {
+		value = "0o" + value
+	}
+
+	var err error
+	switch basic.Kind() {
+	case types.Int:
+		_, err = strconv.ParseInt(value, list.base, list.intBits)
+	case types.Int8:
+		_, err = strconv.ParseInt(value, list.base, 8)
+	case types.Int16:
+		_, err = strconv.ParseInt(value, list.base, 16)
+	case types.Int32:
+		_, err = strconv.ParseInt(value, list.base, 32)
+	case types.Int64:
+		_, err = strconv.ParseInt(value, list.base, 64)
+	case types.Uint:
+		_, err = strconv.ParseUint(value, list.base, list.intBits)
+	case types.Uint8:
+		_, err = strconv.ParseUint(value, list.base, 8)
+	case types.Uint16:
+		_, err = strconv.ParseUint(value, list.base, 16)
+	case types.Uint32:
+		_, err = strconv.ParseUint(value, list.base, 32)
+	case types.Uint64:
+		_, err = strconv.ParseUint(value, list.base, 64)
+	default:
+		return checkBasic(basic, value)
+	}
+	return err
+}
+
+func hasBasePrefix(value string) bool {
+	value = strings.ToLower(strings.TrimLeft(value, "+-"))
+	return strings.HasPrefix(value, "0x") || strings.HasPrefix(value, "0o") || strings.HasPrefix(value, "0b")
+}
+
+func isNamed(typ types.Type, pkg, name string) bool {
+	named, ok := types.Unalias(typ).(*types.Named)
+	return ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == pkg && named.Obj().Name() == name
+}
+
+func isTextUnmarshaler(typ types.Type) bool {
+	obj, _, _ := types.LookupFieldOrMethod(types.NewPointer(typ), false, nil, "UnmarshalText")
+	_, ok := obj.(*types.Func)
+	return ok
+}
+
+// key is an environment variable key and the path of the field using it.
+type key struct {
+	key  string
+	path []string
+}
+
+// checkDuplicates reports the fields of st resolving to the same key as
+// another one, ignoring the ones found within a single field, which are
+// reported on the struct declaring them.
+func checkDuplicates(pass *analysis.Pass, st *types.Struct) {
+	fields := map[string]*types.Var{}
+	for i := 0; i < st.NumFields(); i++ {
+		fields[st.Field(i).Name()] = st.Field(i)
+	}
+
+	seen := map[string][]string{}
+	for _, k := range collectKeys(st, "", nil, map[types.Type]bool{}) {
+		first, ok := seen[k.key]
+		if !ok {
+			seen[k.key] = k.path
+			continue
+		}
+		if first[0] != k.path[0] {
+			pass.Reportf(fields[k.path[0]].Pos(), "duplicate env key %q for fields %s and %s",
+				k.key, strings.Join(first, "."), strings.Join(k.path, "."))
+		}
+	}
+}
+
+// collectKeys returns the keys used by the fields of st, following nested
+// structs as the env package does.
+func collectKeys(st *types.Struct, prefix string, path []string, visiting map[types.Type]bool) []key {
+	var keys []key
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		if !field.Exported() {
+			continue
+		}
+		tag := reflect.StructTag(st.Tag(i))
+		name, _ := parseEnvTag(tag.Get("env"))
+		if name == "-" {
+			continue
+		}
+		fieldPath := append(append([]string(nil), path...), field.Name())
+
+		typ := field.Type()
+		if ptr, ok := typ.Underlying().(*types.Pointer); ok {
+			typ = ptr.Elem()
+		}
+		nested, isStruct := typ.Underlying().(*types.Struct)
+		_, isNamed := typ.(*types.Named)
+		if isStruct && !isNamed {
+			keys = append(keys, collectKeys(nested, prefix+tag.Get("envPrefix"), fieldPath, visiting)...)
+			continue
+		}
+
+		if name != "" {
+			keys = append(keys, key{prefix + name, fieldPath})
+		}
+
+		if isStruct && !visiting[typ] {
+			visiting[typ] = true
+			keys = append(keys, collectKeys(nested, prefix+tag.Get("envPrefix"), fieldPath, visiting)...)
+			delete(visiting, typ)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,12 @@
+package envlint_test
+
+import (
+	"testing"
+
+	"github.com/caarlos0/env/v11/envlint"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), envlint.Analyzer, "a")
+}
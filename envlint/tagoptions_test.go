@@ -0,0 +1,66 @@
+package envlint
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+)
+
+// TestTagOptions checks tagOptions lists the options handled by the switch of
+// parseFieldParams in the env package, so both stay in sync.
+func TestTagOptions(t *testing.T) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "../env.go", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var supported []string
+	ast.Inspect(file, func(n ast.Node) bool {
+		fn, ok := n.(*ast.FuncDecl)
+		if !ok || fn.Name.Name != "parseFieldParams" {
+			return true
+		}
+		ast.Inspect(fn.Body, func(n ast.Node) bool {
+			sw, ok := n.(*ast.SwitchStmt)
+			if !ok {
+				return true
+			}
+			if tag, ok := sw.Tag.(*ast.Ident); !ok || tag.Name != "tag" {
+				return true
+			}
+			for _, stmt := range sw.Body.List {
+				for _, expr := range stmt.(*ast.CaseClause).List {
+					lit, ok := expr.(*ast.BasicLit)
+					if !ok || lit.Kind != token.STRING {
+						continue
+					}
+					option, err := strconv.Unquote(lit.Value)
+					if err != nil {
+						t.Fatal(err)
+					}
+					supported = append(supported, option)
+				}
+			}
+			return false
+		})
+		return false
+	})
+	if len(supported) == 0 {
+		t.Fatal("no tag options found in parseFieldParams")
+	}
+
+	var listed []string
+	for option := range tagOptions {
+		listed = append(listed, option)
+	}
+	sort.Strings(supported)
+	sort.Strings(listed)
+	if !reflect.DeepEqual(supported, listed) {
+		t.Errorf("tagOptions is out of sync with the env package:\ngot:  %q\nwant: %q", listed, supported)
+	}
+}
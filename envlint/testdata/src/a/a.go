@@ -0,0 +1,108 @@
+package a
+
+import (
+	"net"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/caarlos0/env/v11"
+)
+
+type Config struct {
+	Host     string               `env:"HOST,required"`
+	Port     int                  `env:"PORT,requried"`                     // want `unknown env tag option "requried" on field Port`
+	Debug    bool                 `env:"DEBUG,required" envDefault:"false"` // want `field Debug is required, but its envDefault makes it never missing`
+	Ignored  string               `env:"-,nope"`
+	Workers  int                  `env:"WORKERS" envDefault:"many"` // want `invalid envDefault for field Workers: strconv.ParseInt: parsing "many": invalid syntax`
+	Small    int8                 `env:"SMALL" envDefault:"1000"`   // want `invalid envDefault for field Small: strconv.ParseInt: parsing "1000": value out of range`
+	Ratio    *float64             `env:"RATIO" envDefault:"0.5"`
+	Timeout  time.Duration        `env:"TIMEOUT" envDefault:"5"` // want `invalid envDefault for field Timeout: time: missing unit in duration "5"`
+	Endpoint url.URL              `env:"ENDPOINT" envDefault:"https://example.com"`
+	Addr     net.IP               `env:"ADDR" envDefault:"not an ip, but not checked"`
+	Ports    []int                `env:"PORTS" envSeparator:";" envDefault:"80;x"`        // want `invalid envDefault for field Ports: strconv.ParseInt: parsing "x": invalid syntax`
+	Labels   map[string]bool      `env:"LABELS" envDefault:"a:true,b"`                    // want `invalid envDefault for field Labels: "b" should be in "key:value" format`
+	Levels   map[string]uint      `env:"LEVELS" envKeyValSeparator:"=" envDefault:"a=-1"` // want `invalid envDefault for field Levels: strconv.ParseUint: parsing "-1": invalid syntax`
+	Name     env.Optional[string] `env:"NAME" envDefault:"name"`
+	Count    env.Optional[uint16] `env:"COUNT" envDefault:"-1"` // want `invalid envDefault for field Count: strconv.ParseUint: parsing "-1": invalid syntax`
+	Token    *env.Secret[int]     `env:"TOKEN" envDefault:"x"`  // want `invalid envDefault for field Token: strconv.ParseInt: parsing "x": invalid syntax`
+	Cert     int                  `env:"CERT,file" envDefault:"/etc/cert"`
+	Empty    int                  `env:"EMPTY" envDefault:""`
+	Sep      string               `env:"SEP" envSeparator:","` // want `envSeparator on field Sep of type string, which is not a slice, an array or a map`
+	SepPtr   *[]string            `env:"SEP_PTR" envSeparator:","`
+	secret   string               `env:"SECRET"` // want `env tags on unexported field secret are ignored`
+	private  string
+}
+
+type Lists struct {
+	Trimmed []int          `env:"TRIMMED,trimSpace,skipEmpty" envDefault:" 1 , 2 ,"`
+	Quoted  []int          `env:"QUOTED,quoted" envDefault:"\"1\",2"`
+	Pairs   map[string]int `env:"PAIRS,trimSpace" envDefault:"a : 1, b: 2"`
+	Invalid []int          `env:"INVALID,trimSpace" envDefault:"1,,2"` // want `invalid envDefault for field Invalid: strconv.ParseInt: parsing "": invalid syntax`
+}
+
+type Nested struct {
+	Routes  [][]string                   `env:"ROUTES" envSeparator:";," envDefault:"a,b;c"`
+	Ports   map[string][]int             `env:"PORTS" envSeparator:";," envDefault:"http:80,8080;https:443"`
+	Labels  map[string]map[string]string `env:"LABELS" envSeparator:";," envKeyValSeparator:":=" envDefault:"web:tier=front"`
+	Invalid map[string][]int             `env:"INVALID" envSeparator:";," envDefault:"http:80,x"` // want `invalid envDefault for field Invalid: strconv.ParseInt: parsing "x": invalid syntax`
+	Missing [][]int                      `env:"MISSING"`                                          // want `envSeparator "" should have one separator per level on field Missing of type \[\]\[\]int`
+}
+
+type Arrays struct {
+	Ports    [2]int            `env:"PORTS" envDefault:"80,443"`
+	Grid     [2][]int          `env:"GRID" envSeparator:";," envDefault:"1,2;3"`
+	Pointers map[string]*int   `env:"POINTERS" envDefault:"a:1"`
+	Short    [3]int            `env:"SHORT" envDefault:"1,2"`     // want `invalid envDefault for field Short: expected 3 items, got 2`
+	Invalid  map[string]*uint8 `env:"INVALID" envDefault:"a:256"` // want `invalid envDefault for field Invalid: strconv.ParseUint: parsing "256": value out of range`
+}
+
+type Bases struct {
+	Mask    uint32      `env:"MASK" envBase:"16" envDefault:"ff"`
+	Literal int         `env:"LITERAL" envBase:"0" envDefault:"1_000_000"`
+	Big     uint        `env:"BIG" envBase:"10" envDefault:"4294967296"`
+	Mode    os.FileMode `env:"MODE" envBase:"0" envDefault:"644"`
+	Bits    []uint8     `env:"BITS" envBase:"2" envDefault:"101,11"`
+	Bad     uint8       `env:"BAD" envBase:"16" envDefault:"fff"` // want `invalid envDefault for field Bad: strconv.ParseUint: parsing "fff": value out of range`
+	Octal   os.FileMode `env:"OCTAL" envBase:"0" envDefault:"9"`  // want `invalid envDefault for field Octal: strconv.ParseUint: parsing "0o9": invalid syntax`
+	Wrong   int         `env:"WRONG" envBase:"hex"`               // want `invalid envBase "hex" on field Wrong`
+}
+
+type Bools struct {
+	Lenient  bool   `env:"LENIENT,lenientBool" envDefault:"yes"`
+	Lenients []bool `env:"LENIENTS,lenientBool" envDefault:"on,off"`
+	Strict   bool   `env:"STRICT" envDefault:"yes"` // want `invalid envDefault for field Strict: strconv.ParseBool: parsing "yes": invalid syntax`
+}
+
+type Database struct {
+	Host string `env:"HOST"`
+	Port int    `env:"PORT"`
+}
+
+type Server struct {
+	Host     string    `env:"HOST"`
+	Database Database  // want `duplicate env key "HOST" for fields Host and Database.Host`
+	Cache    *Database `envPrefix:"CACHE_"`
+	Replica  Database  `envPrefix:"REPLICA_"`
+	Other    struct {  // want `duplicate env key "REPLICA_PORT" for fields Replica.Port and Other.Port`
+		Port int `env:"REPLICA_PORT"`
+	}
+}
+
+type Inner struct {
+	A struct {
+		Key string `env:"KEY"`
+	}
+	B struct { // want `duplicate env key "KEY" for fields A.Key and B.Key`
+		Key string `env:"KEY"`
+	}
+}
+
+type Outer struct {
+	Inner Inner
+}
+
+type Node struct {
+	Name string `env:"NAME"`
+	Next *Node  `envPrefix:"NEXT_"`
+}
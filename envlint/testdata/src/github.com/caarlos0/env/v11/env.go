@@ -0,0 +1,10 @@
+package env
+
+type Optional[T any] struct {
+	value T
+	ok    bool
+}
+
+type Secret[T any] struct {
+	value T
+}
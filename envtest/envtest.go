@@ -0,0 +1,370 @@
+// Package envtest provides helpers to test code using the env package.
+//
+// Example:
+//
+//	func TestConfig(t *testing.T) {
+//		opts := env.Options{Environment: envtest.Environment("HOST", "localhost")}
+//		_, err := env.ParseAsWithOptions[config](opts)
+//		envtest.AssertErrors(t, err, env.VarIsNotSetError{Key: "PORT"})
+//	}
+package envtest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/caarlos0/env/v11"
+)
+
+var update = flag.Bool("envtest.update", false, "update the golden files of envtest.Golden") //nolint:gochecknoglobals
+
+// Environment returns an environment, to be used as Options.Environment,
+// from a list of keys and values:
+//
+//	envtest.Environment("HOST", "localhost", "PORT", "8080")
+//
+// It panics if the number of arguments is odd.
+func Environment(kv ...string) map[string]string {
+	if len(kv)%2 != 0 {
+		panic("envtest: Environment expects an even number of arguments")
+	}
+	result := make(map[string]string, len(kv)/2)
+	for i := 0; i < len(kv); i += 2 {
+		result[kv[i]] = kv[i+1]
+	}
+	return result
+}
+
+// LoadFile reads an environment from a fixture file, to be used as
+// Options.Environment.
+//
+// The file has one KEY=VALUE pair per line, optionally preceded by "export".
+// Values can be wrapped in single quotes, which are removed, or double quotes,
+// which are unquoted as Go strings. Empty lines and lines starting with '#'
+// are ignored.
+func LoadFile(tb testing.TB, path string) map[string]string {
+	tb.Helper()
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		tb.Fatalf("envtest: %v", err)
+	}
+
+	result := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			tb.Fatalf("envtest: %s:%d: expected KEY=VALUE, got %q", path, n, line)
+		}
+		value, err := unquote(strings.TrimSpace(value))
+		if err != nil {
+			tb.Fatalf("envtest: %s:%d: %v", path, n, err)
+		}
+		result[strings.TrimSpace(key)] = value
+	}
+	return result
+}
+
+func unquote(s string) (string, error) {
+	if len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'' {
+		return s[1 : len(s)-1], nil
+	}
+	if strings.HasPrefix(s, `"`) {
+		return strconv.Unquote(s)
+	}
+	return s, nil
+}
+
+// AssertErrors reports an error if err is not an env.AggregateError holding
+// exactly the expected errors, in any order.
+//
+// An error matches an expected one if they have the same type, and all the
+// exported fields set in the expected one are equal, so
+//
+//	envtest.AssertErrors(t, err, env.VarIsNotSetError{Key: "PORT"})
+//
+// matches any VarIsNotSetError for the PORT variable, regardless of its Path.
+func AssertErrors(tb testing.TB, err error, expected ...error) {
+	tb.Helper()
+
+	var agg env.AggregateError
+	if !errors.As(err, &agg) {
+		tb.Errorf("envtest: expected an env.AggregateError, got %v", err)
+		return
+	}
+
+	remaining := append([]error(nil), agg.Errors...)
+	var missing []error
+	for _, want := range expected {
+		found := false
+		for i, got := range remaining {
+			if matchError(want, got) {
+				remaining = append(remaining[:i], remaining[i+1:]...)
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, want)
+		}
+	}
+
+	for _, err := range missing {
+		tb.Errorf("envtest: missing error %T: %v", err, err)
+	}
+	for _, err := range remaining {
+		tb.Errorf("envtest: unexpected error %T: %v", err, err)
+	}
+}
+
+func matchError(want, got error) bool {
+	wv, gv := reflect.ValueOf(want), reflect.ValueOf(got)
+	if wv.Type() != gv.Type() {
+		return false
+	}
+	if wv.Kind() != reflect.Struct {
+		return reflect.DeepEqual(want, got)
+	}
+	for i := 0; i < wv.NumField(); i++ {
+		if !wv.Type().Field(i).IsExported() || wv.Field(i).IsZero() {
+			continue
+		}
+		if !reflect.DeepEqual(wv.Field(i).Interface(), gv.Field(i).Interface()) {
+			return false
+		}
+	}
+	return true
+}
+
+// Golden compares the output of env.GetFieldParamsWithOptions for v, encoded
+// as JSON, with the contents of the golden file at path, reporting an error if
+// they differ.
+//
+// Run the tests with the -envtest.update flag to write the golden files.
+func Golden(tb testing.TB, v interface{}, opts env.Options, path string) {
+	tb.Helper()
+
+	params, err := env.GetFieldParamsWithOptions(v, opts)
+	if err != nil {
+		tb.Fatalf("envtest: %v", err)
+	}
+	got, err := json.MarshalIndent(params, "", "  ")
+	if err != nil {
+		tb.Fatalf("envtest: %v", err)
+	}
+	got = append(got, '\n')
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			tb.Fatalf("envtest: %v", err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil { //nolint:gosec
+			tb.Fatalf("envtest: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		tb.Fatalf("envtest: %v (run with -envtest.update to create it)", err)
+	}
+	if !bytes.Equal(got, want) {
+		tb.Errorf("envtest: field params do not match %s (run with -envtest.update to update it):\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
+
+// Sample returns a minimal environment in which T can be parsed with opts:
+// all its required fields are set to sample values of their types. Fields that
+// are loaded from files are set to the path of temporary files holding the
+// sample values.
+//
+// It fails the test if such an environment cannot be built.
+func Sample[T any](tb testing.TB, opts env.Options) map[string]string {
+	tb.Helper()
+
+	var t T
+	fields, err := env.GetFieldsWithOptions(&t, opts)
+	if err != nil {
+		tb.Fatalf("envtest: %v", err)
+	}
+
+	result := map[string]string{}
+	for _, field := range fields {
+		if !field.Required && !field.NotEmpty {
+			continue
+		}
+		if field.HasDefaultValue && field.DefaultValue != "" {
+			continue
+		}
+
+		value, ok := sampleValue(field, opts.FuncMap)
+		if !ok {
+			tb.Fatalf("envtest: no sample value for field %q of type %q", field.Path, field.Type)
+		}
+		if field.LoadFile {
+			file := filepath.Join(tb.TempDir(), field.Key)
+			if err := os.WriteFile(file, []byte(value), 0o600); err != nil {
+				tb.Fatalf("envtest: %v", err)
+			}
+			value = file
+		}
+		result[field.Key] = value
+	}
+
+	opts.Environment = result
+	if _, err := env.ParseAsWithOptions[T](opts); err != nil {
+		tb.Fatalf("envtest: sample environment is not valid: %v", err)
+	}
+	return result
+}
+
+var sampleCandidates = []string{ //nolint:gochecknoglobals
+	"sample", "1", "true", "1s", "https://example.com", "UTC", "127.0.0.1",
+}
+
+func sampleValue(field env.Field, funcMap map[reflect.Type]env.ParserFunc) (string, bool) {
+	separators, keyValSeparators, err := env.Separators(reflect.StructField{Type: field.Type, Tag: field.Tag}, funcMap)
+	if err != nil {
+		return "", false
+	}
+	s := sampler{
+		funcMap:          funcMap,
+		boolValues:       field.BoolValues(),
+		separators:       separators,
+		keyValSeparators: keyValSeparators,
+	}
+	return s.value(field.Type)
+}
+
+// sampler builds the sample values of a field, separating the items of each
+// level of its nested slices, arrays and maps as the env package does.
+type sampler struct {
+	funcMap          map[reflect.Type]env.ParserFunc
+	boolValues       map[string]bool
+	separators       []string
+	keyValSeparators []string
+}
+
+func (s sampler) value(typ reflect.Type) (string, bool) {
+	typ = env.ValueType(typ)
+	funcMap := s.funcMap
+
+	if parser, ok := funcMap[typ]; ok {
+		return firstValid(func(v string) bool {
+			_, err := parser(v)
+			return err == nil
+		})
+	}
+
+	if reflect.PtrTo(typ).Implements(textUnmarshalerType) {
+		return firstValid(func(v string) bool {
+			tm := reflect.New(typ).Interface().(interface{ UnmarshalText([]byte) error })
+			return tm.UnmarshalText([]byte(v)) == nil
+		})
+	}
+
+	switch typ {
+	case reflect.TypeOf(time.Nanosecond):
+		return "1s", true
+	case reflect.TypeOf(url.URL{}):
+		return "https://example.com", true
+	case reflect.TypeOf(time.Location{}):
+		return "UTC", true
+	}
+
+	switch typ.Kind() {
+	case reflect.String:
+		return "sample", true
+	case reflect.Bool:
+		return s.boolValue()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "1", true
+	case reflect.Float32, reflect.Float64:
+		return "1.5", true
+	case reflect.Slice:
+		return s.next(typ).value(typ.Elem())
+	case reflect.Array:
+		item, ok := s.next(typ).value(typ.Elem())
+		if !ok {
+			return "", false
+		}
+		items := make([]string, typ.Len())
+		for i := range items {
+			items[i] = item
+		}
+		return strings.Join(items, s.separators[0]), true
+	case reflect.Map:
+		key, ok := s.next(typ).value(typ.Key())
+		if !ok {
+			return "", false
+		}
+		value, ok := s.next(typ).value(typ.Elem())
+		if !ok {
+			return "", false
+		}
+		return key + s.keyValSeparators[0] + value, true
+	}
+	return "", false
+}
+
+// boolValue returns the first value of the lenient boolean vocabulary, if
+// any, meaning true.
+func (s sampler) boolValue() (string, bool) {
+	if s.boolValues == nil {
+		return "true", true
+	}
+	values := make([]string, 0, len(s.boolValues))
+	for v, b := range s.boolValues {
+		if b {
+			values = append(values, v)
+		}
+	}
+	if len(values) == 0 {
+		return "", false
+	}
+	sort.Strings(values)
+	return values[0], true
+}
+
+// next returns the sampler of the items of a collection of type typ.
+func (s sampler) next(typ reflect.Type) sampler {
+	next := s
+	if len(s.separators) > 0 {
+		next.separators = s.separators[1:]
+	}
+	if typ.Kind() == reflect.Map && len(s.keyValSeparators) > 0 {
+		next.keyValSeparators = s.keyValSeparators[1:]
+	}
+	return next
+}
+
+func firstValid(valid func(string) bool) (string, bool) {
+	for _, v := range sampleCandidates {
+		if valid(v) {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+var textUnmarshalerType = reflect.TypeOf((*interface{ UnmarshalText([]byte) error })(nil)).Elem() //nolint:gochecknoglobals
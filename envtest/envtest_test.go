@@ -0,0 +1,218 @@
+package envtest
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/caarlos0/env/v11"
+)
+
+type fakeTB struct {
+	testing.TB
+	errors int
+	fatal  bool
+}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Errorf(string, ...interface{}) { f.errors++ }
+
+func (f *fakeTB) Fatalf(string, ...interface{}) {
+	f.fatal = true
+	panic(f)
+}
+
+func fails(tb testing.TB, fn func(tb testing.TB)) (ftb *fakeTB) {
+	tb.Helper()
+	ftb = &fakeTB{TB: tb}
+	defer func() {
+		if r := recover(); r != nil && r != ftb {
+			panic(r)
+		}
+	}()
+	fn(ftb)
+	return ftb
+}
+
+func TestEnvironment(t *testing.T) {
+	got := Environment("HOST", "localhost", "PORT", "8080")
+	want := map[string]string{"HOST": "localhost", "PORT": "8080"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic")
+		}
+	}()
+	Environment("HOST")
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	content := `# comment
+HOST=localhost
+
+export PORT = 8080
+SINGLE='a "quoted" $value'
+DOUBLE="line\nbreak"
+EMPTY=
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	got := LoadFile(t, path)
+	want := map[string]string{
+		"HOST":   "localhost",
+		"PORT":   "8080",
+		"SINGLE": `a "quoted" $value`,
+		"DOUBLE": "line\nbreak",
+		"EMPTY":  "",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	t.Run("invalid line", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), ".env")
+		if err := os.WriteFile(path, []byte("NOPE\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if !fails(t, func(tb testing.TB) { LoadFile(tb, path) }).fatal {
+			t.Error("expected a fatal error")
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		if !fails(t, func(tb testing.TB) { LoadFile(tb, "nope.env") }).fatal {
+			t.Error("expected a fatal error")
+		}
+	})
+}
+
+type config struct {
+	Host    string        `env:"HOST,required"`
+	Port    int           `env:"PORT,required"`
+	Timeout time.Duration `env:"TIMEOUT" envDefault:"5s"`
+	Level   string        `env:"LOG_LEVEL" envDefault:"info" envDefault.prod:"warn" envEnum:"debug,info,warn"`
+}
+
+func TestAssertErrors(t *testing.T) {
+	_, err := env.ParseAsWithOptions[config](env.Options{
+		Environment: Environment("PORT", "nope"),
+	})
+
+	AssertErrors(t, err,
+		env.VarIsNotSetError{Key: "HOST"},
+		env.ParseError{Name: "Port"},
+	)
+
+	for name, expected := range map[string][]error{
+		"missing":    {env.VarIsNotSetError{Key: "HOST"}},
+		"unexpected": {env.VarIsNotSetError{Key: "HOST"}, env.ParseError{Name: "Port"}, env.EmptyVarError{}},
+		"mismatch":   {env.VarIsNotSetError{Key: "PORT"}, env.ParseError{Name: "Port"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if fails(t, func(tb testing.TB) { AssertErrors(tb, err, expected...) }).errors == 0 {
+				t.Error("expected errors")
+			}
+		})
+	}
+
+	t.Run("not aggregate", func(t *testing.T) {
+		if fails(t, func(tb testing.TB) { AssertErrors(tb, nil) }).errors == 0 {
+			t.Error("expected errors")
+		}
+	})
+}
+
+func TestGolden(t *testing.T) {
+	path := filepath.Join("testdata", "config.golden.json")
+	Golden(t, &config{}, env.Options{}, path)
+
+	t.Run("mismatch", func(t *testing.T) {
+		if *update {
+			t.Skip("updating golden files")
+		}
+		if fails(t, func(tb testing.TB) { Golden(tb, &config{}, env.Options{Prefix: "APP_"}, path) }).errors == 0 {
+			t.Error("expected errors")
+		}
+	})
+}
+
+type level int
+
+func (l *level) UnmarshalText(b []byte) error {
+	if string(b) != "1" {
+		return os.ErrInvalid
+	}
+	*l = 1
+	return nil
+}
+
+type sampled struct {
+	Config   config
+	Name     env.Optional[string]         `env:"NAME,required"`
+	Password env.Secret[string]           `env:"PASSWORD,required,file"`
+	Ratio    *float64                     `env:"RATIO,notEmpty"`
+	Hosts    []string                     `env:"HOSTS,required"`
+	Labels   map[string]int               `env:"LABELS,required" envKeyValSeparator:"="`
+	Ports    [2]int                       `env:"PORTS,required" envSeparator:";"`
+	Routes   map[string]map[string]string `env:"ROUTES,required" envSeparator:";," envKeyValSeparator:":="`
+	Level    level                        `env:"LEVEL,required"`
+	Timeout  time.Duration                `env:"TIMEOUT,required"`
+	Optional string                       `env:"OPTIONAL"`
+	Default  string                       `env:"DEFAULT,required" envDefault:"value"`
+	Nested   struct {
+		Debug bool `env:"DEBUG,required"`
+	} `envPrefix:"NESTED_"`
+}
+
+func TestSample(t *testing.T) {
+	got := Sample[sampled](t, env.Options{})
+
+	keys := map[string]bool{}
+	for k := range got {
+		keys[k] = true
+	}
+	want := map[string]bool{
+		"HOST": true, "PORT": true, "NAME": true, "PASSWORD": true, "RATIO": true, "HOSTS": true,
+		"LABELS": true, "PORTS": true, "ROUTES": true, "LEVEL": true, "TIMEOUT": true, "NESTED_DEBUG": true,
+	}
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("got keys %v, want %v", keys, want)
+	}
+	if got["LABELS"] != "sample=1" {
+		t.Errorf("got LABELS %q", got["LABELS"])
+	}
+	if got["PORTS"] != "1;1" {
+		t.Errorf("got PORTS %q", got["PORTS"])
+	}
+	if got["ROUTES"] != "sample:sample=sample" {
+		t.Errorf("got ROUTES %q", got["ROUTES"])
+	}
+
+	t.Run("lenient bool", func(t *testing.T) {
+		type config struct {
+			Debug bool `env:"DEBUG,required,lenientBool"`
+		}
+		got := Sample[config](t, env.Options{BoolValues: map[string]bool{"oui": true, "non": false}})
+		if got["DEBUG"] != "oui" {
+			t.Errorf("got DEBUG %q", got["DEBUG"])
+		}
+	})
+
+	t.Run("no sample", func(t *testing.T) {
+		type config struct {
+			Ch chan int `env:"CH,required"`
+		}
+		if !fails(t, func(tb testing.TB) { Sample[config](tb, env.Options{}) }).fatal {
+			t.Error("expected a fatal error")
+		}
+	})
+}
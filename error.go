@@ -1,8 +1,11 @@
 package env
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
 )
 
@@ -17,6 +20,9 @@ import (
 // EmptyVarError
 // LoadFileContentError
 // ParseValueError
+// ExclusiveVarsError
+// UnknownVariantError
+// DuplicateKeyError
 type AggregateError struct {
 	Errors []error
 }
@@ -56,21 +62,159 @@ func (e AggregateError) Is(err error) bool {
 	return false
 }
 
+// MarshalJSON encodes the errors as a list of objects with their type,
+// message, and, when available, the field path, the environment variable key,
+// and the offending value.
+func (e AggregateError) MarshalJSON() ([]byte, error) {
+	type jsonError struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+		Path    string `json:"path,omitempty"`
+		Key     string `json:"key,omitempty"`
+		Value   string `json:"value,omitempty"`
+	}
+
+	errs := make([]jsonError, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		je := jsonError{
+			Type:    reflect.TypeOf(err).Name(),
+			Message: err.Error(),
+		}
+		if fe, ok := err.(FieldError); ok {
+			je.Path = fe.FieldPath()
+			je.Key = fe.EnvKey()
+		}
+		if pe, ok := err.(ParseError); ok {
+			je.Value = pe.Value
+		}
+		errs = append(errs, je)
+	}
+
+	return json.Marshal(struct {
+		Errors []jsonError `json:"errors"`
+	}{errs})
+}
+
+// FieldError is implemented by all errors related to a specific field, so
+// they can be inspected with errors.As:
+//
+//	var ferr env.FieldError
+//	if errors.As(err, &ferr) {
+//		fmt.Println(ferr.FieldPath(), ferr.EnvKey())
+//	}
+type FieldError interface {
+	error
+
+	// FieldPath is the path of the field in the parsed struct, e.g.
+	// "Server.TLS.CertFile" or "Workers[2].Queue".
+	FieldPath() string
+
+	// EnvKey is the environment variable key of the field, prefixes included.
+	EnvKey() string
+}
+
+// withField fills in the path, key and value of field errors that do not
+// have them yet.
+func withField(err error, path, key, value string) error {
+	switch e := err.(type) {
+	case AggregateError:
+		for i := range e.Errors {
+			e.Errors[i] = withField(e.Errors[i], path, key, value)
+		}
+		return e
+	case ParseError:
+		if e.Path == "" {
+			e.Path, e.Key, e.Value = path, key, value
+		}
+		return e
+	case NoParserError:
+		if e.Path == "" {
+			e.Path, e.Key = path, key
+		}
+		return e
+	case NoSupportedTagOptionError:
+		if e.Path == "" {
+			e.Path, e.Key = path, key
+		}
+		return e
+	case VarIsNotSetError:
+		if e.Path == "" {
+			e.Path = path
+		}
+		return e
+	case EmptyVarError:
+		if e.Path == "" {
+			e.Path = path
+		}
+		return e
+	case LoadFileContentError:
+		if e.Path == "" {
+			e.Path = path
+		}
+		return e
+	}
+	return err
+}
+
+// redact hides value from the message of the underlying error of a ParseError.
+func redact(err error, value string) error {
+	if e, ok := err.(ParseError); ok && value != "" && e.Err != nil {
+		e.Err = redactedError{e.Err}
+		return e
+	}
+	return err
+}
+
+// redactedError hides the value, or the part of it, that failed to parse from
+// the message of the error it wraps. Parsers may quote any part of the value
+// in their errors, so only the messages of strconv errors are kept, without
+// their input.
+type redactedError struct {
+	err error
+}
+
+func (e redactedError) Error() string {
+	var numErr *strconv.NumError
+	if errors.As(e.err, &numErr) {
+		return fmt.Sprintf("strconv.%s: parsing %q: %v", numErr.Func, redacted, numErr.Err)
+	}
+	return fmt.Sprintf("invalid value %q", redacted)
+}
+
+func (e redactedError) Unwrap() error { return e.err }
+
 // ParseError occurs when it's impossible to convert the value for given type.
 type ParseError struct {
 	Name string
 	Type reflect.Type
 	Err  error
+
+	// Path is the path of the field in the parsed struct.
+	Path string
+	// Key is the environment variable key of the field.
+	Key string
+	// Value is the value that could not be parsed. It is empty for
+	// sensitive fields and for values loaded from files.
+	Value string
 }
 
 func newParseError(sf reflect.StructField, err error) error {
-	return ParseError{sf.Name, sf.Type, err}
+	return ParseError{Name: sf.Name, Type: sf.Type, Err: err}
 }
 
 func (e ParseError) Error() string {
 	return fmt.Sprintf("parse error on field %q of type %q: %v", e.Name, e.Type, e.Err)
 }
 
+// Unwrap returns the underlying parser error.
+func (e ParseError) Unwrap() error { return e.Err }
+
+// FieldPath implements FieldError.
+func (e ParseError) FieldPath() string { return e.Path }
+
+// EnvKey implements FieldError.
+func (e ParseError) EnvKey() string { return e.Key }
+
 // NotStructPtrError occurs when pass something that is not a pointer to a struct to Parse.
 type NotStructPtrError struct{}
 
@@ -82,31 +226,55 @@ func (e NotStructPtrError) Error() string {
 type NoParserError struct {
 	Name string
 	Type reflect.Type
+
+	// Path is the path of the field in the parsed struct.
+	Path string
+	// Key is the environment variable key of the field.
+	Key string
 }
 
 func newNoParserError(sf reflect.StructField) error {
-	return NoParserError{sf.Name, sf.Type}
+	return NoParserError{Name: sf.Name, Type: sf.Type}
 }
 
 func (e NoParserError) Error() string {
 	return fmt.Sprintf("no parser found for field %q of type %q", e.Name, e.Type)
 }
 
-// NoSupportedTagOptionError occurs when the given tag is not supported.
-// Built-in supported tags: "", "file", "required", "unset", "notEmpty",
-// "expand", "envDefault", and "envSeparator".
+// FieldPath implements FieldError.
+func (e NoParserError) FieldPath() string { return e.Path }
+
+// EnvKey implements FieldError.
+func (e NoParserError) EnvKey() string { return e.Key }
+
+// NoSupportedTagOptionError occurs when an option of the `env` tag is not
+// supported. The supported options are "file", "keyFile", "required",
+// "unset", "notEmpty", "expand", "sensitive", "quoted", "trimSpace",
+// "skipEmpty", "init", "initIfSet", "lenientBool" and "-", as documented in
+// the README.
 type NoSupportedTagOptionError struct {
 	Tag string
+
+	// Path is the path of the field in the parsed struct.
+	Path string
+	// Key is the environment variable key of the field.
+	Key string
 }
 
 func newNoSupportedTagOptionError(tag string) error {
-	return NoSupportedTagOptionError{tag}
+	return NoSupportedTagOptionError{Tag: tag}
 }
 
 func (e NoSupportedTagOptionError) Error() string {
 	return fmt.Sprintf("tag option %q not supported", e.Tag)
 }
 
+// FieldPath implements FieldError.
+func (e NoSupportedTagOptionError) FieldPath() string { return e.Path }
+
+// EnvKey implements FieldError.
+func (e NoSupportedTagOptionError) EnvKey() string { return e.Key }
+
 // EnvVarIsNotSetError occurs when the required variable is not set.
 //
 // Deprecated: use VarIsNotSetError.
@@ -115,16 +283,25 @@ type EnvVarIsNotSetError = VarIsNotSetError
 // VarIsNotSetError occurs when the required variable is not set.
 type VarIsNotSetError struct {
 	Key string
+
+	// Path is the path of the field in the parsed struct.
+	Path string
 }
 
 func newVarIsNotSetError(key string) error {
-	return VarIsNotSetError{key}
+	return VarIsNotSetError{Key: key}
 }
 
 func (e VarIsNotSetError) Error() string {
 	return fmt.Sprintf(`required environment variable %q is not set`, e.Key)
 }
 
+// FieldPath implements FieldError.
+func (e VarIsNotSetError) FieldPath() string { return e.Path }
+
+// EnvKey implements FieldError.
+func (e VarIsNotSetError) EnvKey() string { return e.Key }
+
 // EmptyEnvVarError occurs when the variable which must be not empty is existing but has an empty value
 //
 // Deprecated: use EmptyVarError.
@@ -133,31 +310,52 @@ type EmptyEnvVarError = EmptyVarError
 // EmptyVarError occurs when the variable which must be not empty is existing but has an empty value
 type EmptyVarError struct {
 	Key string
+
+	// Path is the path of the field in the parsed struct.
+	Path string
 }
 
 func newEmptyVarError(key string) error {
-	return EmptyVarError{key}
+	return EmptyVarError{Key: key}
 }
 
 func (e EmptyVarError) Error() string {
 	return fmt.Sprintf("environment variable %q should not be empty", e.Key)
 }
 
+// FieldPath implements FieldError.
+func (e EmptyVarError) FieldPath() string { return e.Path }
+
+// EnvKey implements FieldError.
+func (e EmptyVarError) EnvKey() string { return e.Key }
+
 // LoadFileContentError occurs when it's impossible to load the value from the file.
 type LoadFileContentError struct {
 	Filename string
 	Key      string
 	Err      error
+
+	// Path is the path of the field in the parsed struct.
+	Path string
 }
 
 func newLoadFileContentError(filename, key string, err error) error {
-	return LoadFileContentError{filename, key, err}
+	return LoadFileContentError{Filename: filename, Key: key, Err: err}
 }
 
 func (e LoadFileContentError) Error() string {
 	return fmt.Sprintf("could not load content of file %q from variable %s: %v", e.Filename, e.Key, e.Err)
 }
 
+// Unwrap returns the underlying file system error.
+func (e LoadFileContentError) Unwrap() error { return e.Err }
+
+// FieldPath implements FieldError.
+func (e LoadFileContentError) FieldPath() string { return e.Path }
+
+// EnvKey implements FieldError.
+func (e LoadFileContentError) EnvKey() string { return e.Key }
+
 // ParseValueError occurs when it's impossible to convert value using given parser.
 type ParseValueError struct {
 	Msg string
@@ -171,3 +369,65 @@ func newParseValueError(message string, err error) error {
 func (e ParseValueError) Error() string {
 	return fmt.Sprintf("%s: %v", e.Msg, e.Err)
 }
+
+// Unwrap returns the underlying error.
+func (e ParseValueError) Unwrap() error { return e.Err }
+
+// ExclusiveVarsError occurs when more than one variable of an `envExclusive`
+// group is set.
+type ExclusiveVarsError struct {
+	Group string
+	Keys  []string
+}
+
+func newExclusiveVarsError(group string, keys []string) error {
+	return ExclusiveVarsError{group, keys}
+}
+
+func (e ExclusiveVarsError) Error() string {
+	return fmt.Sprintf("environment variables %q are mutually exclusive (group %q), but more than one is set", e.Keys, e.Group)
+}
+
+// UnknownVariantError occurs when the variable of an interface field does not
+// match any of the variants registered for its type.
+type UnknownVariantError struct {
+	Key      string
+	Path     string
+	Value    string
+	Variants []string
+}
+
+func newUnknownVariantError(key, path, value string, variants []string) error {
+	return UnknownVariantError{key, path, value, variants}
+}
+
+func (e UnknownVariantError) Error() string {
+	return fmt.Sprintf("environment variable %q has unknown variant %q, expected one of %q", e.Key, e.Value, e.Variants)
+}
+
+// FieldPath implements FieldError.
+func (e UnknownVariantError) FieldPath() string { return e.Path }
+
+// EnvKey implements FieldError.
+func (e UnknownVariantError) EnvKey() string { return e.Key }
+
+// DuplicateKeyError occurs when two fields resolve to the same key.
+type DuplicateKeyError struct {
+	Key string
+	// Paths are the paths of the two fields, in the order they were parsed.
+	Paths [2]string
+}
+
+func newDuplicateKeyError(key, first, second string) error {
+	return DuplicateKeyError{key, [2]string{first, second}}
+}
+
+func (e DuplicateKeyError) Error() string {
+	return fmt.Sprintf("environment variable %q is used by both %s and %s", e.Key, e.Paths[0], e.Paths[1])
+}
+
+// FieldPath implements FieldError.
+func (e DuplicateKeyError) FieldPath() string { return e.Paths[1] }
+
+// EnvKey implements FieldError.
+func (e DuplicateKeyError) EnvKey() string { return e.Key }
@@ -154,6 +154,26 @@ func ExampleParse_init() {
 	// Output: <nil> &{HI}
 }
 
+// Use `initIfSet` to initialize nil pointers to structs only if any of their
+// variables is set, so optional sub-configurations stay nil otherwise.
+func ExampleParse_initIfSet() {
+	type Redis struct {
+		Addr string `env:"ADDR,required"`
+	}
+	type Config struct {
+		Redis *Redis `env:",initIfSet" envPrefix:"REDIS_"`
+		Cache *Redis `env:",initIfSet" envPrefix:"CACHE_"`
+	}
+	var cfg Config
+	if err := ParseWithOptions(&cfg, Options{
+		Environment: map[string]string{"CACHE_ADDR": "localhost:6379"},
+	}); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Print(cfg.Redis, cfg.Cache)
+	// Output: <nil> &{localhost:6379}
+}
+
 // You can define the default value for a field by either using the
 // `envDefault` tag, or when initializing the `struct`.
 //
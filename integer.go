@@ -0,0 +1,45 @@
+package env
+
+import (
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var fileModeType = reflect.TypeOf(os.FileMode(0)) //nolint:gochecknoglobals
+
+// parseInteger parses value into a new integer of type typ, in the given
+// base, using the full width of typ: int and uint are 64 bits wide on 64-bit
+// platforms. Base 0 accepts the 0x, 0o, 0b and 0 prefixes and the _ separators
+// of Go integer literals, and parses os.FileMode values without a prefix as
+// octal, e.g. "644". It reports false if typ is not an integer type.
+func parseInteger(typ reflect.Type, value string, base int) (reflect.Value, bool, error) {
+	if typ == fileModeType && base == 0 && !hasBasePrefix(value) {
+		value = "0o" + value
+	}
+
+	result := reflect.New(typ).Elem()
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(value, base, typ.Bits())
+		if err != nil {
+			return reflect.Value{}, true, err
+		}
+		result.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(value, base, typ.Bits())
+		if err != nil {
+			return reflect.Value{}, true, err
+		}
+		result.SetUint(u)
+	default:
+		return reflect.Value{}, false, nil
+	}
+	return result, true, nil
+}
+
+func hasBasePrefix(value string) bool {
+	value = strings.ToLower(strings.TrimLeft(value, "+-"))
+	return strings.HasPrefix(value, "0x") || strings.HasPrefix(value, "0o") || strings.HasPrefix(value, "0b")
+}
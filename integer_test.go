@@ -0,0 +1,137 @@
+package env
+
+import (
+	"errors"
+	"math"
+	"os"
+	"strconv"
+	"testing"
+)
+
+func TestIntegerLiterals(t *testing.T) {
+	type Config struct {
+		Hex     int               `env:"HEX"`
+		Octal   int               `env:"OCTAL"`
+		Binary  uint8             `env:"BINARY"`
+		Legacy  int               `env:"LEGACY"`
+		Big     int               `env:"BIG"`
+		BigUint uint              `env:"BIG_UINT"`
+		Mode    os.FileMode       `env:"MODE"`
+		Masks   map[string]uint16 `env:"MASKS"`
+		Ptr     *int64            `env:"PTR"`
+		Default int32             `env:"DEFAULT" envDefault:"1_000"`
+	}
+
+	cfg, err := ParseAsWithOptions[Config](Options{
+		IntegerLiterals: true,
+		Environment: map[string]string{
+			"HEX":      "0x1F",
+			"OCTAL":    "0o17",
+			"BINARY":   "0b1010",
+			"LEGACY":   "017",
+			"BIG":      strconv.Itoa(math.MaxInt),
+			"BIG_UINT": strconv.FormatUint(math.MaxUint, 10),
+			"MODE":     "644",
+			"MASKS":    "a:0xff,b:1_000",
+			"PTR":      "-0x10",
+		},
+	})
+	isNoErr(t, err)
+	isEqual(t, 31, cfg.Hex)
+	isEqual(t, 15, cfg.Octal)
+	isEqual(t, uint8(10), cfg.Binary)
+	isEqual(t, 15, cfg.Legacy)
+	isEqual(t, math.MaxInt, cfg.Big)
+	isEqual(t, uint(math.MaxUint), cfg.BigUint)
+	isEqual(t, os.FileMode(0o644), cfg.Mode)
+	isEqual(t, map[string]uint16{"a": 255, "b": 1000}, cfg.Masks)
+	isEqual(t, int64(-16), *cfg.Ptr)
+	isEqual(t, int32(1000), cfg.Default)
+}
+
+func TestIntegerLiteralsFileMode(t *testing.T) {
+	type Config struct {
+		Modes []os.FileMode `env:"MODES"`
+	}
+
+	cfg, err := ParseAsWithOptions[Config](Options{
+		IntegerLiterals: true,
+		Environment:     map[string]string{"MODES": "644,0755,0o600,0x1ff,0b111"},
+	})
+	isNoErr(t, err)
+	isEqual(t, []os.FileMode{0o644, 0o755, 0o600, 0o777, 0o7}, cfg.Modes)
+}
+
+func TestIntegerLiteralsDisabled(t *testing.T) {
+	type Config struct {
+		Hex  int         `env:"HEX"`
+		Mode os.FileMode `env:"MODE"`
+	}
+
+	cfg, err := ParseAsWithOptions[Config](Options{Environment: map[string]string{"MODE": "644"}})
+	isNoErr(t, err)
+	isEqual(t, os.FileMode(644), cfg.Mode)
+
+	_, err = ParseAsWithOptions[Config](Options{Environment: map[string]string{"HEX": "0x1F"}})
+	isErrorWithMessage(t, err, `env: parse error on field "Hex" of type "int": strconv.ParseInt: parsing "0x1F": invalid syntax`)
+}
+
+func TestEnvBase(t *testing.T) {
+	type Config struct {
+		Hex     uint32         `env:"HEX" envBase:"16"`
+		Binary  []int          `env:"BINARY" envBase:"2"`
+		Literal int            `env:"LITERAL" envBase:"0"`
+		Decimal int            `env:"DECIMAL" envBase:"10"`
+		Mode    os.FileMode    `env:"MODE" envBase:"0"`
+		Masks   map[uint8]bool `env:"MASKS" envBase:"16"`
+		Plain   int            `env:"PLAIN"`
+		Name    string         `env:"NAME" envBase:"16"`
+	}
+
+	cfg, err := ParseAsWithOptions[Config](Options{Environment: map[string]string{
+		"HEX":     "ff",
+		"BINARY":  "101,11",
+		"LITERAL": "0x_ff",
+		"DECIMAL": "010",
+		"MODE":    "0750",
+		"MASKS":   "f:true",
+		"PLAIN":   "010",
+		"NAME":    "ff",
+	}})
+	isNoErr(t, err)
+	isEqual(t, uint32(255), cfg.Hex)
+	isEqual(t, []int{5, 3}, cfg.Binary)
+	isEqual(t, 255, cfg.Literal)
+	isEqual(t, 10, cfg.Decimal)
+	isEqual(t, os.FileMode(0o750), cfg.Mode)
+	isEqual(t, map[uint8]bool{15: true}, cfg.Masks)
+	isEqual(t, 10, cfg.Plain)
+	isEqual(t, "ff", cfg.Name)
+}
+
+func TestEnvBaseErrors(t *testing.T) {
+	type Config struct {
+		Hex    uint8 `env:"HEX" envBase:"16"`
+		Binary int   `env:"BINARY" envBase:"2"`
+	}
+
+	_, err := ParseAsWithOptions[Config](Options{Environment: map[string]string{
+		"HEX":    "fff",
+		"BINARY": "12",
+	}})
+	isErrorWithMessage(t, err, `env: parse error on field "Hex" of type "uint8": strconv.ParseUint: parsing "fff": value out of range; `+
+		`parse error on field "Binary" of type "int": strconv.ParseInt: parsing "12": invalid syntax`)
+	isTrue(t, errors.Is(err, ParseError{}))
+
+	type Invalid struct {
+		Port int `env:"PORT" envBase:"hex"`
+	}
+	_, err = ParseAsWithOptions[Invalid](Options{})
+	isErrorWithMessage(t, err, `env: parse error on field "Port" of type "int": invalid envBase "hex"`)
+
+	type OutOfRange struct {
+		Port int `env:"PORT" envBase:"37"`
+	}
+	err = Check[OutOfRange](Options{})
+	isErrorWithMessage(t, err, `env: parse error on field "Port" of type "int": invalid envBase "37"`)
+}
@@ -0,0 +1,153 @@
+package env
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// KubernetesManifests holds the Kubernetes manifests and container snippets
+// generated by Kubernetes, in YAML.
+type KubernetesManifests struct {
+	// ConfigMap holds the variables that are not sensitive.
+	ConfigMap []byte
+
+	// Secret holds the sensitive variables. It is nil if there are none.
+	Secret []byte
+
+	// Env is the `env:` section of a container, referencing each variable
+	// of the ConfigMap and the Secret.
+	Env []byte
+
+	// EnvFrom is the `envFrom:` section of a container, referencing the
+	// ConfigMap and the Secret.
+	EnvFrom []byte
+}
+
+// Kubernetes generates a ConfigMap and a Secret named name, holding the
+// variables read when parsing v with opts, and the container snippets using
+// them.
+//
+// Sensitive variables go to the Secret, and the others to the ConfigMap.
+// Their values are looked up in opts.Environment, which is not filled with the
+// current environment if nil, and default to their `envDefault` tag. Optional
+// variables without a value are left out, and required ones are left empty.
+//
+// opts.Environment also determines the number of items in slices of structs,
+// as when parsing.
+//
+// The variables of the variants registered with RegisterVariant are preceded
+// by a comment naming their variant. Only the variant selected by
+// opts.Environment, or by the default value of its field, has required
+// variables: the variables of the other ones are left out unless they have a
+// value.
+func Kubernetes(v interface{}, name string, opts Options) (KubernetesManifests, error) {
+	if opts.Environment == nil {
+		opts.Environment = map[string]string{}
+	}
+
+	fields, err := schemaFields(v, opts)
+	if err != nil {
+		return KubernetesManifests{}, err
+	}
+
+	var configMap, secret strings.Builder
+	var env []string
+	seen := map[string]bool{}
+	// selected maps the keys of the variant fields to their selected variant.
+	selected := map[string]string{}
+	// variants holds the variant of the last variable written to each data
+	// section, and to env.
+	variants := map[*strings.Builder]string{}
+	var envVariant string
+	for _, field := range fields {
+		if seen[field.Key] {
+			continue
+		}
+		seen[field.Key] = true
+
+		value, ok := opts.Environment[field.Key]
+		if !ok || value == "" && field.HasDefaultValue {
+			value, ok = field.DefaultValue, field.HasDefaultValue
+		}
+
+		active := field.variantKey == ""
+		if !active {
+			variant, ok := selected[field.variantKey]
+			active = ok && variant == field.variant
+		}
+		if active && ok {
+			selected[field.Key] = value
+		}
+		if !ok && (!active || !field.Required && !field.NotEmpty) {
+			continue
+		}
+
+		data, ref := &configMap, "configMapKeyRef"
+		if field.Sensitive {
+			data, ref = &secret, "secretKeyRef"
+		}
+		var comment string
+		if field.variantKey != "" {
+			comment = fmt.Sprintf("  # %s, when %s is %q\n", parentPath(field.Path), field.variantKey, field.variant)
+		}
+		// Blank lines separate the variables of each variant from the
+		// others.
+		if comment != variants[data] {
+			if data.Len() > 0 {
+				data.WriteString("\n")
+			}
+			variants[data] = comment
+			data.WriteString(comment)
+		}
+		if comment != envVariant {
+			if len(env) > 0 {
+				env = append(env, "\n")
+			}
+			envVariant = comment
+			env = append(env, comment)
+		}
+		fmt.Fprintf(data, "  %s: %s\n", strconv.Quote(field.Key), strconv.Quote(value))
+		env = append(env, fmt.Sprintf(
+			"  - name: %[1]s\n    valueFrom:\n      %[2]s:\n        name: %[3]s\n        key: %[1]s\n",
+			strconv.Quote(field.Key), ref, strconv.Quote(name),
+		))
+	}
+
+	var result KubernetesManifests
+	var envFrom strings.Builder
+	envFrom.WriteString("envFrom:\n")
+
+	result.ConfigMap = kubernetesManifest("ConfigMap", name, "data", configMap.String())
+	fmt.Fprintf(&envFrom, "  - configMapRef:\n      name: %s\n", strconv.Quote(name))
+
+	if secret.Len() > 0 {
+		result.Secret = kubernetesManifest("Secret", name, "stringData", secret.String())
+		fmt.Fprintf(&envFrom, "  - secretRef:\n      name: %s\n", strconv.Quote(name))
+	}
+
+	if len(env) == 0 {
+		result.Env = []byte("env: []\n")
+	} else {
+		result.Env = []byte("env:\n" + strings.Join(env, ""))
+	}
+	result.EnvFrom = []byte(envFrom.String())
+
+	return result, nil
+}
+
+func kubernetesManifest(kind, name, dataKey, data string) []byte {
+	var sb strings.Builder
+	sb.WriteString("apiVersion: v1\n")
+	fmt.Fprintf(&sb, "kind: %s\n", kind)
+	fmt.Fprintf(&sb, "metadata:\n  name: %s\n", strconv.Quote(name))
+	if kind == "Secret" {
+		sb.WriteString("type: Opaque\n")
+	}
+	if data == "" {
+		fmt.Fprintf(&sb, "%s: {}\n", dataKey)
+	} else {
+		fmt.Fprintf(&sb, "%s:\n%s", dataKey, data)
+	}
+	return []byte(sb.String())
+}
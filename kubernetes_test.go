@@ -0,0 +1,200 @@
+package env
+
+import "testing"
+
+func TestKubernetes(t *testing.T) {
+	type Worker struct {
+		Queue string `env:"QUEUE,required"`
+	}
+
+	type Config struct {
+		Host     string         `env:"HOST,required"`
+		Port     int            `env:"PORT" envDefault:"8080"`
+		Debug    bool           `env:"DEBUG"`
+		Motd     string         `env:"MOTD" envDefault:"hello \"world\""`
+		Password Secret[string] `env:"PASSWORD,required"`
+		Token    string         `env:"TOKEN,sensitive"`
+		Workers  []Worker       `envPrefix:"WORKER"`
+	}
+
+	m, err := Kubernetes(&Config{}, "app", Options{
+		Prefix: "APP_",
+		Environment: map[string]string{
+			"APP_HOST":           "example.com",
+			"APP_PORT":           "",
+			"APP_TOKEN":          "s3cr3t",
+			"APP_WORKER_0_QUEUE": "high",
+			"APP_WORKER_1_QUEUE": "low",
+		},
+	})
+	isNoErr(t, err)
+
+	isEqual(t, `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: "app"
+data:
+  "APP_HOST": "example.com"
+  "APP_PORT": "8080"
+  "APP_MOTD": "hello \"world\""
+  "APP_WORKER_0_QUEUE": "high"
+  "APP_WORKER_1_QUEUE": "low"
+`, string(m.ConfigMap))
+
+	isEqual(t, `apiVersion: v1
+kind: Secret
+metadata:
+  name: "app"
+type: Opaque
+stringData:
+  "APP_PASSWORD": ""
+  "APP_TOKEN": "s3cr3t"
+`, string(m.Secret))
+
+	isEqual(t, `env:
+  - name: "APP_HOST"
+    valueFrom:
+      configMapKeyRef:
+        name: "app"
+        key: "APP_HOST"
+  - name: "APP_PORT"
+    valueFrom:
+      configMapKeyRef:
+        name: "app"
+        key: "APP_PORT"
+  - name: "APP_MOTD"
+    valueFrom:
+      configMapKeyRef:
+        name: "app"
+        key: "APP_MOTD"
+  - name: "APP_PASSWORD"
+    valueFrom:
+      secretKeyRef:
+        name: "app"
+        key: "APP_PASSWORD"
+  - name: "APP_TOKEN"
+    valueFrom:
+      secretKeyRef:
+        name: "app"
+        key: "APP_TOKEN"
+  - name: "APP_WORKER_0_QUEUE"
+    valueFrom:
+      configMapKeyRef:
+        name: "app"
+        key: "APP_WORKER_0_QUEUE"
+  - name: "APP_WORKER_1_QUEUE"
+    valueFrom:
+      configMapKeyRef:
+        name: "app"
+        key: "APP_WORKER_1_QUEUE"
+`, string(m.Env))
+
+	isEqual(t, `envFrom:
+  - configMapRef:
+      name: "app"
+  - secretRef:
+      name: "app"
+`, string(m.EnvFrom))
+}
+
+func TestKubernetesEmpty(t *testing.T) {
+	type Config struct {
+		Debug bool `env:"DEBUG"`
+	}
+
+	t.Setenv("DEBUG", "true")
+
+	m, err := Kubernetes(&Config{}, "app", Options{})
+	isNoErr(t, err)
+	isEqual(t, "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: \"app\"\ndata: {}\n", string(m.ConfigMap))
+	isEqual(t, []byte(nil), m.Secret)
+	isEqual(t, "env: []\n", string(m.Env))
+	isEqual(t, "envFrom:\n  - configMapRef:\n      name: \"app\"\n", string(m.EnvFrom))
+}
+
+func TestKubernetesError(t *testing.T) {
+	type Config struct {
+		Host string `env:"HOST,nope"`
+	}
+
+	_, err := Kubernetes(&Config{}, "app", Options{})
+	isErrorWithMessage(t, err, `env: tag option "nope" not supported`)
+}
+
+func TestKubernetesVariants(t *testing.T) {
+	type Config struct {
+		Storage storage `env:"STORAGE" envPrefix:"STORAGE_" envDefault:"s3"`
+		Debug   bool    `env:"DEBUG" envDefault:"false"`
+	}
+
+	opts := Options{Environment: map[string]string{"STORAGE": "gcs"}}
+	RegisterVariant[storage, s3Storage](&opts, "s3")
+	RegisterVariant[storage, gcsStorage](&opts, "gcs")
+
+	m, err := Kubernetes(&Config{}, "app", opts)
+	isNoErr(t, err)
+	isEqual(t, `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: "app"
+data:
+  "STORAGE": "gcs"
+
+  # Storage, when STORAGE is "gcs"
+  "STORAGE_GCS_BUCKET": ""
+
+  # Storage, when STORAGE is "s3"
+  "STORAGE_S3_REGION": "us-east-1"
+
+  "DEBUG": "false"
+`, string(m.ConfigMap))
+
+	isEqual(t, `env:
+  - name: "STORAGE"
+    valueFrom:
+      configMapKeyRef:
+        name: "app"
+        key: "STORAGE"
+
+  # Storage, when STORAGE is "gcs"
+  - name: "STORAGE_GCS_BUCKET"
+    valueFrom:
+      configMapKeyRef:
+        name: "app"
+        key: "STORAGE_GCS_BUCKET"
+
+  # Storage, when STORAGE is "s3"
+  - name: "STORAGE_S3_REGION"
+    valueFrom:
+      configMapKeyRef:
+        name: "app"
+        key: "STORAGE_S3_REGION"
+
+  - name: "DEBUG"
+    valueFrom:
+      configMapKeyRef:
+        name: "app"
+        key: "DEBUG"
+`, string(m.Env))
+
+	t.Run("default", func(t *testing.T) {
+		opts := opts
+		opts.Environment = map[string]string{}
+
+		m, err := Kubernetes(&Config{}, "app", opts)
+		isNoErr(t, err)
+		isEqual(t, `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: "app"
+data:
+  "STORAGE": "s3"
+
+  # Storage, when STORAGE is "s3"
+  "STORAGE_S3_BUCKET": ""
+  "STORAGE_S3_REGION": "us-east-1"
+
+  "DEBUG": "false"
+`, string(m.ConfigMap))
+	})
+}
@@ -0,0 +1,78 @@
+package env
+
+import "reflect"
+
+// Optional holds a value of type T, and whether it was set.
+//
+// It can be used for fields that need to tell an unset variable apart from one
+// set to the zero value of T, e.g.:
+//
+//	type Config struct {
+//		Retries env.Optional[int] `env:"RETRIES"`
+//	}
+//
+// Variables set to an empty string are considered unset, while values from
+// `envDefault` are considered set.
+type Optional[T any] struct {
+	value T
+	ok    bool
+}
+
+// NewOptional returns an Optional set to value.
+func NewOptional[T any](value T) Optional[T] {
+	return Optional[T]{value: value, ok: true}
+}
+
+// Get returns the value, and whether it was set.
+func (o Optional[T]) Get() (T, bool) {
+	return o.value, o.ok
+}
+
+// Or returns the value if it was set, and def otherwise.
+func (o Optional[T]) Or(def T) T {
+	if o.ok {
+		return o.value
+	}
+	return def
+}
+
+func (o *Optional[T]) wrapped() reflect.Value {
+	return reflect.ValueOf(&o.value).Elem()
+}
+
+func (o *Optional[T]) markSet() {
+	o.ok = true
+}
+
+// wrapper is implemented by types that wrap another value that is parsed
+// in their place, like Optional.
+type wrapper interface {
+	// wrapped returns the addressable value to parse into.
+	wrapped() reflect.Value
+	// markSet is called after the wrapped value was set.
+	markSet()
+}
+
+// ValueType returns the type of the value parsed into a field of type t,
+// looking through pointers and the types wrapping it, like Optional and
+// Secret.
+func ValueType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if w := asWrapper(reflect.New(t).Elem()); w != nil {
+		return ValueType(w.wrapped().Type())
+	}
+	return t
+}
+
+func asWrapper(field reflect.Value) wrapper {
+	if !field.CanAddr() {
+		return nil
+	}
+	w, ok := field.Addr().Interface().(wrapper)
+	if !ok {
+		return nil
+	}
+	return w
+}
@@ -0,0 +1,107 @@
+package env
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestOptional(t *testing.T) {
+	type custom struct{ v string }
+	type Config struct {
+		Int         Optional[int]            `env:"INT"`
+		Zero        Optional[int]            `env:"ZERO"`
+		Unset       Optional[int]            `env:"UNSET"`
+		Empty       Optional[string]         `env:"EMPTY"`
+		Default     Optional[bool]           `env:"DEFAULT" envDefault:"false"`
+		Duration    Optional[time.Duration]  `env:"DURATION"`
+		URL         Optional[url.URL]        `env:"URL"`
+		Unmarshaler Optional[unmarshaler]    `env:"UNMARSHALER"`
+		Strings     Optional[[]string]       `env:"STRINGS"`
+		Map         Optional[map[string]int] `env:"MAP"`
+		Ptr         Optional[*int]           `env:"PTR"`
+		Custom      Optional[custom]         `env:"CUSTOM"`
+	}
+
+	cfg, err := ParseAsWithOptions[Config](Options{
+		Environment: map[string]string{
+			"INT":         "10",
+			"ZERO":        "0",
+			"EMPTY":       "",
+			"DURATION":    "1m",
+			"URL":         "https://example.com",
+			"UNMARSHALER": "2s",
+			"STRINGS":     "a,b",
+			"MAP":         "a:1",
+			"PTR":         "3",
+			"CUSTOM":      "foo",
+		},
+		FuncMap: map[reflect.Type]ParserFunc{
+			reflect.TypeOf(custom{}): func(v string) (interface{}, error) {
+				return custom{v}, nil
+			},
+		},
+	})
+	isNoErr(t, err)
+
+	v, ok := cfg.Int.Get()
+	isEqual(t, 10, v)
+	isTrue(t, ok)
+
+	v, ok = cfg.Zero.Get()
+	isEqual(t, 0, v)
+	isTrue(t, ok)
+
+	v, ok = cfg.Unset.Get()
+	isEqual(t, 0, v)
+	isFalse(t, ok)
+	isEqual(t, 5, cfg.Unset.Or(5))
+	isEqual(t, 10, cfg.Int.Or(5))
+
+	_, ok = cfg.Empty.Get()
+	isFalse(t, ok)
+
+	isEqual(t, NewOptional(false), cfg.Default)
+	isEqual(t, NewOptional(time.Minute), cfg.Duration)
+	u, _ := cfg.URL.Get()
+	isEqual(t, "example.com", u.Host)
+	isEqual(t, NewOptional(unmarshaler{2 * time.Second}), cfg.Unmarshaler)
+	isEqual(t, NewOptional([]string{"a", "b"}), cfg.Strings)
+	isEqual(t, NewOptional(map[string]int{"a": 1}), cfg.Map)
+	p, ok := cfg.Ptr.Get()
+	isTrue(t, ok)
+	isEqual(t, 3, *p)
+	isEqual(t, NewOptional(custom{"foo"}), cfg.Custom)
+}
+
+func TestOptionalParseError(t *testing.T) {
+	type Config struct {
+		Int Optional[int] `env:"INT"`
+	}
+
+	cfg, err := ParseAsWithOptions[Config](Options{
+		Environment: map[string]string{"INT": "nope"},
+	})
+	isErrorWithMessage(t, err, `env: parse error on field "Int" of type "int": strconv.ParseInt: parsing "nope": invalid syntax`)
+	isTrue(t, errors.Is(err, ParseError{}))
+	_, ok := cfg.Int.Get()
+	isFalse(t, ok)
+}
+
+func TestOptionalRequired(t *testing.T) {
+	type Config struct {
+		Int Optional[int] `env:"INT,required"`
+	}
+
+	_, err := ParseAsWithOptions[Config](Options{Environment: map[string]string{}})
+	isTrue(t, errors.Is(err, VarIsNotSetError{}))
+}
+
+func TestValueType(t *testing.T) {
+	isEqual(t, reflect.TypeOf(0), ValueType(reflect.TypeOf(Optional[int]{})))
+	isEqual(t, reflect.TypeOf(""), ValueType(reflect.TypeOf(&Secret[string]{})))
+	isEqual(t, reflect.TypeOf(0), ValueType(reflect.TypeOf(Optional[*int]{})))
+	isEqual(t, reflect.TypeOf([]string{}), ValueType(reflect.TypeOf([]string{})))
+}
@@ -0,0 +1,125 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ExitConfig is the exit status used by MustOrExit, EX_CONFIG from sysexits.h.
+const ExitConfig = 78
+
+var (
+	osExit           = os.Exit   //nolint:gochecknoglobals
+	stderr io.Writer = os.Stderr //nolint:gochecknoglobals
+)
+
+type reportSection struct {
+	title string
+	hint  string
+	lines []string
+}
+
+// FormatReport renders the errors returned by the parse functions as a
+// readable, multi-line report, grouping them by kind: missing required
+// variables, empty variables, invalid values, file errors, and everything else.
+//
+// It returns an empty string if err is nil.
+func FormatReport(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	errs := []error{err}
+	var agg AggregateError
+	if errors.As(err, &agg) {
+		errs = agg.Errors
+	}
+
+	missing := reportSection{
+		title: "Missing required variables",
+		hint:  "set them, or declare a default value with `envDefault`",
+	}
+	empty := reportSection{
+		title: "Empty variables",
+		hint:  "these variables are set, but must have a value",
+	}
+	invalid := reportSection{
+		title: "Invalid values",
+		hint:  "check the values match the expected types and formats",
+	}
+	files := reportSection{
+		title: "File errors",
+		hint:  "check the files exist and are readable",
+	}
+	other := reportSection{
+		title: "Other errors",
+	}
+
+	for _, err := range errs {
+		switch e := err.(type) {
+		case VarIsNotSetError:
+			missing.lines = append(missing.lines, reportSubject(e.Key, e.Path))
+		case EmptyVarError:
+			empty.lines = append(empty.lines, reportSubject(e.Key, e.Path))
+		case ParseError:
+			line := fmt.Sprintf("%s: %v", reportSubject(e.Key, e.Path), e.Err)
+			if e.Value != "" {
+				line += fmt.Sprintf(" (got %q)", e.Value)
+			}
+			invalid.lines = append(invalid.lines, line)
+		case LoadFileContentError:
+			files.lines = append(files.lines, fmt.Sprintf("%s: %q: %v", reportSubject(e.Key, e.Path), e.Filename, e.Err))
+		case FieldError:
+			other.lines = append(other.lines, fmt.Sprintf("%s: %v", reportSubject(e.EnvKey(), e.FieldPath()), e))
+		default:
+			other.lines = append(other.lines, err.Error())
+		}
+	}
+
+	var sb strings.Builder
+	if len(errs) == 1 {
+		sb.WriteString("env: 1 problem found in the environment\n")
+	} else {
+		fmt.Fprintf(&sb, "env: %d problems found in the environment\n", len(errs))
+	}
+	for _, section := range []reportSection{missing, empty, invalid, files, other} {
+		if len(section.lines) == 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, "\n%s:\n", section.title)
+		for _, line := range section.lines {
+			fmt.Fprintf(&sb, "  - %s\n", line)
+		}
+		if section.hint != "" {
+			fmt.Fprintf(&sb, "  hint: %s\n", section.hint)
+		}
+	}
+	return sb.String()
+}
+
+func reportSubject(key, path string) string {
+	switch {
+	case key == "":
+		return path
+	case path == "":
+		return key
+	default:
+		return fmt.Sprintf("%s (%s)", key, path)
+	}
+}
+
+// MustOrExit prints the FormatReport of err to the standard error and exits
+// with ExitConfig if err is not nil.
+//
+//	cfg, err := env.ParseAs[config]()
+//	env.MustOrExit(err)
+func MustOrExit(err error) {
+	if err == nil {
+		return
+	}
+	fmt.Fprint(stderr, FormatReport(err))
+	osExit(ExitConfig)
+}
@@ -0,0 +1,97 @@
+package env
+
+import (
+	"bytes"
+	"errors"
+	"runtime"
+	"testing"
+)
+
+func TestFormatReport(t *testing.T) {
+	type Config struct {
+		Host     string `env:"HOST,required"`
+		Port     int    `env:"PORT,required"`
+		User     string `env:"USER,notEmpty"`
+		Password string `env:"PASSWORD,file"`
+		Timeout  int    `env:"TIMEOUT"`
+		Nested   struct {
+			Name string `env:"NAME,nope"`
+		} `envPrefix:"NESTED_"`
+	}
+
+	err := ParseWithOptions(&Config{}, Options{
+		Prefix: "APP_",
+		Environment: map[string]string{
+			"APP_USER":     "",
+			"APP_PASSWORD": "/does/not/exist",
+			"APP_TIMEOUT":  "soon",
+		},
+	})
+	oserr := "no such file or directory"
+	if runtime.GOOS == "windows" {
+		oserr = "The system cannot find the path specified."
+	}
+	isEqual(t, `env: 6 problems found in the environment
+
+Missing required variables:
+  - APP_HOST (Host)
+  - APP_PORT (Port)
+  hint: set them, or declare a default value with `+"`envDefault`"+`
+
+Empty variables:
+  - APP_USER (User)
+  hint: these variables are set, but must have a value
+
+Invalid values:
+  - APP_TIMEOUT (Timeout): strconv.ParseInt: parsing "soon": invalid syntax (got "soon")
+  hint: check the values match the expected types and formats
+
+File errors:
+  - APP_PASSWORD (Password): "/does/not/exist": open /does/not/exist: `+oserr+`
+  hint: check the files exist and are readable
+
+Other errors:
+  - APP_NESTED_NAME (Nested.Name): tag option "nope" not supported
+`, FormatReport(err))
+}
+
+func TestFormatReportSingle(t *testing.T) {
+	isEqual(t, "", FormatReport(nil))
+	isEqual(t, `env: 1 problem found in the environment
+
+Other errors:
+  - expected a pointer to a Struct
+`, FormatReport(Parse(nil)))
+	isEqual(t, `env: 1 problem found in the environment
+
+Other errors:
+  - boom
+`, FormatReport(errors.New("boom")))
+}
+
+func TestMustOrExit(t *testing.T) {
+	var code int
+	var out bytes.Buffer
+	oldExit, oldStderr := osExit, stderr
+	osExit = func(c int) { code = c }
+	stderr = &out
+	t.Cleanup(func() {
+		osExit, stderr = oldExit, oldStderr
+	})
+
+	MustOrExit(nil)
+	isEqual(t, 0, code)
+	isEqual(t, "", out.String())
+
+	type Config struct {
+		Host string `env:"HOST,required"`
+	}
+	MustOrExit(ParseWithOptions(&Config{}, Options{Environment: map[string]string{}}))
+	isEqual(t, ExitConfig, code)
+	isEqual(t, `env: 1 problem found in the environment
+
+Missing required variables:
+  - HOST (Host)
+  hint: set them, or declare a default value with `+"`envDefault`"+`
+`, out.String())
+}
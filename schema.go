@@ -0,0 +1,311 @@
+package env
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+type jsonSchema struct {
+	Schema     string                        `json:"$schema"`
+	Type       string                        `json:"type"`
+	Properties map[string]jsonSchemaProperty `json:"properties"`
+	Required   []string                      `json:"required,omitempty"`
+	AllOf      []jsonSchemaCondition         `json:"allOf,omitempty"`
+}
+
+// jsonSchemaCondition requires the variables of a variant when the variable
+// selecting it has its name.
+type jsonSchemaCondition struct {
+	If struct {
+		Properties map[string]jsonSchemaConst `json:"properties"`
+		Required   []string                   `json:"required"`
+	} `json:"if"`
+	Then struct {
+		Required []string `json:"required"`
+	} `json:"then"`
+}
+
+type jsonSchemaConst struct {
+	Const string `json:"const"`
+}
+
+func newJSONSchemaCondition(key, value string) jsonSchemaCondition {
+	var condition jsonSchemaCondition
+	condition.If.Properties = map[string]jsonSchemaConst{key: {Const: value}}
+	condition.If.Required = []string{key}
+	return condition
+}
+
+type jsonSchemaProperty struct {
+	Type        string        `json:"type"`
+	Format      string        `json:"format,omitempty"`
+	Description string        `json:"description,omitempty"`
+	Default     interface{}   `json:"default,omitempty"`
+	Enum        []interface{} `json:"enum,omitempty"`
+	Pattern     string        `json:"pattern,omitempty"`
+	WriteOnly   bool          `json:"writeOnly,omitempty"`
+}
+
+// JSONSchema returns a JSON Schema document describing the environment
+// variables read when parsing v with opts.
+//
+// Each variable is a property of the schema, with the JSON type matching its
+// field type (boolean, integer, number or string), a format for URLs (uri),
+// durations (duration) and IP addresses (ip), its default value, the values of
+// its `envEnum` tag and its `envDescription` tag. Sensitive variables are
+// marked as write-only, and their default values are left out. Required
+// variables without a default value are listed as required. The variables of the variants
+// registered with RegisterVariant are described too, and are only required
+// when their variant is selected.
+//
+// It returns an error if a default value, or a value of an `envEnum` tag,
+// does not match the JSON type of its variable.
+func JSONSchema(v interface{}, opts Options) ([]byte, error) {
+	fields, err := schemaFields(v, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := jsonSchema{
+		Schema:     jsonSchemaDraft,
+		Type:       "object",
+		Properties: map[string]jsonSchemaProperty{},
+	}
+	var agrErr AggregateError
+	conditions := map[[2]string]int{}
+	for _, field := range fields {
+		if field.Ignored {
+			continue
+		}
+
+		typ := ValueType(field.Type)
+		property := jsonSchemaProperty{
+			Type:        jsonType(typ),
+			Format:      jsonFormat(typ),
+			Description: field.Description,
+		}
+		if _, ok := opts.FuncMap[typ]; ok {
+			// Custom parsers may accept any string.
+			property.Type = "string"
+		}
+		if property.Type == "integer" && field.HasBase && field.Base != 10 {
+			// Integer literals, or integers in another base, are not JSON
+			// integers.
+			property.Type = "string"
+		}
+		if property.Type == "boolean" && field.LenientBool {
+			property.Type = "string"
+			if field.Enum() == nil {
+				// Lenient booleans are matched regardless of their case.
+				property.Pattern = caseInsensitivePattern(sortedBoolValues(field.BoolValues()))
+			}
+		}
+		if field.Sensitive {
+			property.WriteOnly = true
+		}
+		for _, v := range field.Enum() {
+			value, err := jsonValue(property.Type, v)
+			if err != nil {
+				agrErr.Errors = append(agrErr.Errors, schemaValueError(field, v, err))
+				continue
+			}
+			property.Enum = append(property.Enum, value)
+		}
+		if property.Enum == nil && len(opts.Variants[typ]) > 0 {
+			for _, name := range variantNames(opts.Variants[typ]) {
+				property.Enum = append(property.Enum, name)
+			}
+		}
+		if field.variantKey != "" {
+			used := fmt.Sprintf("Only used when %s is %q.", field.variantKey, field.variant)
+			property.Description = strings.TrimSpace(property.Description + " " + used)
+		}
+		if field.HasDefaultValue {
+			value, err := jsonValue(property.Type, field.DefaultValue)
+			if err != nil {
+				agrErr.Errors = append(agrErr.Errors, schemaValueError(field, field.DefaultValue, err))
+				continue
+			}
+			if !field.Sensitive {
+				property.Default = value
+			}
+		}
+		schema.Properties[field.Key] = property
+
+		if !field.Required || field.HasDefaultValue {
+			continue
+		}
+		if field.variantKey == "" {
+			schema.Required = append(schema.Required, field.Key)
+			continue
+		}
+		selector := [2]string{field.variantKey, field.variant}
+		i, ok := conditions[selector]
+		if !ok {
+			i = len(schema.AllOf)
+			conditions[selector] = i
+			schema.AllOf = append(schema.AllOf, newJSONSchemaCondition(field.variantKey, field.variant))
+		}
+		schema.AllOf[i].Then.Required = append(schema.AllOf[i].Then.Required, field.Key)
+	}
+	if len(agrErr.Errors) > 0 {
+		return nil, agrErr
+	}
+	sort.Strings(schema.Required)
+
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// schemaValueError returns the ParseError of a value of field that does not
+// match its JSON type, redacting it if the field is sensitive.
+func schemaValueError(field schemaField, value string, err error) error {
+	sf := reflect.StructField{Name: field.Path[strings.LastIndex(field.Path, ".")+1:], Type: field.Type}
+	err = newParseError(sf, err)
+	if field.Sensitive {
+		err, value = redact(err, value), ""
+	}
+	return withField(err, field.Path, field.Key, value)
+}
+
+// schemaField is a field described by JSONSchema, with the key and the value
+// selecting the variant it belongs to, if any.
+type schemaField struct {
+	Field
+	variantKey string
+	variant    string
+}
+
+// schemaFields returns the fields of v, like GetFieldsWithOptions, followed
+// by the fields of the variants registered for its interface fields.
+func schemaFields(v interface{}, opts Options) ([]schemaField, error) {
+	opts.Dirs = nil
+	opts, err := customOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields []schemaField
+	var collect func(variantKey, variant string) processFieldFn
+	collect = func(variantKey, variant string) processFieldFn {
+		return func(_ reflect.Value, refTypeField reflect.StructField, opts Options, fieldParams FieldParams) error {
+			if fieldParams.OwnKey == "" {
+				return nil
+			}
+			fields = append(fields, schemaField{
+				Field: Field{
+					FieldParams: fieldParams,
+					Path:        fieldPath(refTypeField, opts),
+					Type:        refTypeField.Type,
+					Tag:         refTypeField.Tag,
+				},
+				variantKey: variantKey,
+				variant:    variant,
+			})
+
+			variants, ok := opts.Variants[refTypeField.Type]
+			if !ok || refTypeField.Type.Kind() != reflect.Interface {
+				return nil
+			}
+			for _, name := range variantNames(variants) {
+				variantOpts := optionsWithEnvPrefix(refTypeField, opts)
+				variantOpts.Prefix += RelaxedKeyMatcher(name) + string(underscore)
+				if err := doParse(reflect.New(variants[name]).Elem(), collect(fieldParams.Key, name), variantOpts); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+	if err := parseInternal(v, collect("", ""), opts); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+func jsonType(t reflect.Type) string {
+	if _, ok := reflect.New(t).Interface().(encoding.TextUnmarshaler); ok {
+		return "string"
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if t == reflect.TypeOf(time.Nanosecond) {
+			return "string"
+		}
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	default:
+		return "string"
+	}
+}
+
+func jsonFormat(t reflect.Type) string {
+	switch t {
+	case reflect.TypeOf(url.URL{}):
+		return "uri"
+	case reflect.TypeOf(time.Nanosecond):
+		return "duration"
+	case reflect.TypeOf(net.IP{}), reflect.TypeOf(netip.Addr{}):
+		return "ip"
+	}
+	return ""
+}
+
+// caseInsensitivePattern returns a regular expression matching any of values,
+// regardless of their case. JSON Schema patterns have no flags, so each letter
+// is matched by a class holding both of its cases.
+func caseInsensitivePattern(values []string) string {
+	var sb strings.Builder
+	sb.WriteString("^(")
+	for i, v := range values {
+		if i > 0 {
+			sb.WriteByte('|')
+		}
+		for _, r := range v {
+			upper, lower := unicode.ToUpper(r), unicode.ToLower(r)
+			if upper == lower {
+				sb.WriteString(regexp.QuoteMeta(string(r)))
+				continue
+			}
+			sb.WriteString("[" + string(upper) + string(lower) + "]")
+		}
+	}
+	sb.WriteString(")$")
+	return sb.String()
+}
+
+// jsonValue converts a default value to the JSON type of its variable.
+func jsonValue(typ, value string) (interface{}, error) {
+	switch typ {
+	case "boolean":
+		return strconv.ParseBool(value)
+	case "integer":
+		i, err := strconv.ParseInt(value, 10, 64)
+		if err == nil {
+			return i, nil
+		}
+		if u, uerr := strconv.ParseUint(value, 10, 64); uerr == nil {
+			return u, nil
+		}
+		return nil, err
+	case "number":
+		return strconv.ParseFloat(value, 64)
+	}
+	return value, nil
+}
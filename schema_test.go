@@ -0,0 +1,119 @@
+package env
+
+import (
+	"encoding/json"
+	"net"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJSONSchema(t *testing.T) {
+	type Config struct {
+		Host     string           `env:"HOST,required" envDescription:"Address to listen on."`
+		Port     int              `env:"PORT" envDefault:"8080"`
+		Debug    bool             `env:"DEBUG" envDefault:"false"`
+		Ratio    *float64         `env:"RATIO" envDefault:"0.5"`
+		Level    string           `env:"LEVEL,required" envDefault:"info" envEnum:"debug,info,warn"`
+		Endpoint url.URL          `env:"ENDPOINT"`
+		Timeout  time.Duration    `env:"TIMEOUT" envDefault:"5s"`
+		Addr     net.IP           `env:"ADDR"`
+		Tags     []string         `env:"TAGS"`
+		Name     Optional[string] `env:"NAME"`
+		Token    Secret[string]   `env:"TOKEN,required"`
+		Password string           `env:"PASSWORD,sensitive" envDefault:"hunter2"`
+		Retries  int              `env:"RETRIES" envDefault:"3" envEnum:"1,3,5"`
+		Count    Optional[uint]   `env:"COUNT" envDefault:"3"`
+		Storage  storage          `env:"STORAGE" envPrefix:"STORAGE_"`
+		Mask     uint32           `env:"MASK" envBase:"16" envDefault:"ff"`
+		Decimal  int64            `env:"DECIMAL" envBase:"10" envDefault:"10"`
+		Verbose  bool             `env:"VERBOSE,lenientBool" envDefault:"yes"`
+		Ignored  string           `env:"-"`
+		Nested   struct {
+			Key int8 `env:"KEY"`
+		} `envPrefix:"NESTED_"`
+	}
+
+	opts := Options{}
+	RegisterVariant[storage, s3Storage](&opts, "s3")
+	RegisterVariant[storage, gcsStorage](&opts, "gcs")
+
+	b, err := JSONSchema(&Config{}, opts)
+	isNoErr(t, err)
+	isFalse(t, strings.Contains(string(b), "hunter2"))
+
+	var schema map[string]interface{}
+	isNoErr(t, json.Unmarshal(b, &schema))
+
+	isEqual(t, "https://json-schema.org/draft/2020-12/schema", schema["$schema"])
+	isEqual(t, "object", schema["type"])
+	isEqual(t, []interface{}{"HOST", "TOKEN"}, schema["required"])
+	isEqual(t, map[string]interface{}{
+		"HOST":               map[string]interface{}{"type": "string", "description": "Address to listen on."},
+		"PORT":               map[string]interface{}{"type": "integer", "default": float64(8080)},
+		"DEBUG":              map[string]interface{}{"type": "boolean", "default": false},
+		"RATIO":              map[string]interface{}{"type": "number", "default": 0.5},
+		"LEVEL":              map[string]interface{}{"type": "string", "default": "info", "enum": []interface{}{"debug", "info", "warn"}},
+		"ENDPOINT":           map[string]interface{}{"type": "string", "format": "uri"},
+		"TIMEOUT":            map[string]interface{}{"type": "string", "format": "duration", "default": "5s"},
+		"ADDR":               map[string]interface{}{"type": "string", "format": "ip"},
+		"TAGS":               map[string]interface{}{"type": "string"},
+		"NAME":               map[string]interface{}{"type": "string"},
+		"TOKEN":              map[string]interface{}{"type": "string", "writeOnly": true},
+		"PASSWORD":           map[string]interface{}{"type": "string", "writeOnly": true},
+		"RETRIES":            map[string]interface{}{"type": "integer", "default": float64(3), "enum": []interface{}{float64(1), float64(3), float64(5)}},
+		"COUNT":              map[string]interface{}{"type": "integer", "default": float64(3)},
+		"STORAGE":            map[string]interface{}{"type": "string", "enum": []interface{}{"gcs", "s3"}},
+		"STORAGE_GCS_BUCKET": map[string]interface{}{"type": "string", "description": `Only used when STORAGE is "gcs".`},
+		"STORAGE_S3_BUCKET":  map[string]interface{}{"type": "string", "description": `Only used when STORAGE is "s3".`},
+		"STORAGE_S3_REGION":  map[string]interface{}{"type": "string", "default": "us-east-1", "description": `Only used when STORAGE is "s3".`},
+		"MASK":               map[string]interface{}{"type": "string", "default": "ff"},
+		"DECIMAL":            map[string]interface{}{"type": "integer", "default": float64(10)},
+		"VERBOSE": map[string]interface{}{"type": "string", "default": "yes", "pattern": "^(0|1|" +
+			"[Dd][Ii][Ss][Aa][Bb][Ll][Ee]|[Dd][Ii][Ss][Aa][Bb][Ll][Ee][Dd]|[Ee][Nn][Aa][Bb][Ll][Ee]|[Ee][Nn][Aa][Bb][Ll][Ee][Dd]|" +
+			"[Ff]|[Ff][Aa][Ll][Ss][Ee]|[Nn]|[Nn][Oo]|[Oo][Ff][Ff]|[Oo][Nn]|[Tt]|[Tt][Rr][Uu][Ee]|[Yy]|[Yy][Ee][Ss])$"},
+		"NESTED_KEY": map[string]interface{}{"type": "integer"},
+	}, schema["properties"])
+	isEqual(t, []interface{}{
+		map[string]interface{}{
+			"if": map[string]interface{}{
+				"properties": map[string]interface{}{"STORAGE": map[string]interface{}{"const": "gcs"}},
+				"required":   []interface{}{"STORAGE"},
+			},
+			"then": map[string]interface{}{"required": []interface{}{"STORAGE_GCS_BUCKET"}},
+		},
+		map[string]interface{}{
+			"if": map[string]interface{}{
+				"properties": map[string]interface{}{"STORAGE": map[string]interface{}{"const": "s3"}},
+				"required":   []interface{}{"STORAGE"},
+			},
+			"then": map[string]interface{}{"required": []interface{}{"STORAGE_S3_BUCKET"}},
+		},
+	}, schema["allOf"])
+}
+
+func TestJSONSchemaError(t *testing.T) {
+	type Config struct {
+		Host string `env:"HOST,nope"`
+	}
+
+	_, err := JSONSchema(&Config{}, Options{})
+	isErrorWithMessage(t, err, `env: tag option "nope" not supported`)
+
+	_, err = JSONSchema(Config{}, Options{})
+	isErrorWithMessage(t, err, "env: expected a pointer to a Struct")
+
+	type Defaults struct {
+		Count Optional[uint] `env:"COUNT" envDefault:"nope"`
+		Ratio float64        `env:"RATIO" envDefault:"half"`
+		Token int            `env:"TOKEN,sensitive" envDefault:"s3cr3t"`
+		Port  int            `env:"PORT" envEnum:"80,https"`
+	}
+
+	_, err = JSONSchema(&Defaults{}, Options{})
+	isErrorWithMessage(t, err, `env: parse error on field "Count" of type "env.Optional[uint]": strconv.ParseInt: parsing "nope": invalid syntax; `+
+		`parse error on field "Ratio" of type "float64": strconv.ParseFloat: parsing "half": invalid syntax; `+
+		`parse error on field "Token" of type "int": strconv.ParseInt: parsing "[REDACTED]": invalid syntax; `+
+		`parse error on field "Port" of type "int": strconv.ParseInt: parsing "https": invalid syntax`)
+}
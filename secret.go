@@ -0,0 +1,99 @@
+package env
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+const redacted = "[REDACTED]"
+
+// Secret holds a value of type T that is never printed nor serialized: its
+// String, GoString, Format, MarshalJSON and MarshalText methods all return
+// "[REDACTED]". The value is only accessible with Reveal.
+//
+// It is parsed as T would be, except for Secret[[]byte], which holds the raw
+// bytes of the value. Fields of this type are considered `sensitive`.
+//
+//	type Config struct {
+//		Password env.Secret[string] `env:"PASSWORD,file"`
+//	}
+type Secret[T any] struct {
+	value T
+}
+
+// NewSecret returns a Secret holding value.
+func NewSecret[T any](value T) Secret[T] {
+	return Secret[T]{value: value}
+}
+
+// Reveal returns the secret value.
+func (s Secret[T]) Reveal() T {
+	return s.value
+}
+
+// Wipe zeroes the secret value. If T is a []byte, its contents are
+// overwritten with zeros as well.
+func (s *Secret[T]) Wipe() {
+	if b, ok := any(s.value).([]byte); ok {
+		for i := range b {
+			b[i] = 0
+		}
+	}
+	var zero T
+	s.value = zero
+}
+
+// String implements fmt.Stringer.
+func (s Secret[T]) String() string {
+	return redacted
+}
+
+// GoString implements fmt.GoStringer.
+func (s Secret[T]) GoString() string {
+	return redacted
+}
+
+// Format implements fmt.Formatter.
+func (s Secret[T]) Format(f fmt.State, _ rune) {
+	_, _ = f.Write([]byte(redacted))
+}
+
+// MarshalJSON implements json.Marshaler.
+func (s Secret[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(redacted)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s Secret[T]) MarshalText() ([]byte, error) {
+	return []byte(redacted), nil
+}
+
+func (s *Secret[T]) wrapped() reflect.Value {
+	return reflect.ValueOf(&s.value).Elem()
+}
+
+func (s *Secret[T]) markSet() {}
+
+// setBytes sets the value to the raw bytes of v if T is []byte.
+func (s *Secret[T]) setBytes(v string) bool {
+	b, ok := any(&s.value).(*[]byte)
+	if ok {
+		*b = []byte(v)
+	}
+	return ok
+}
+
+func (s *Secret[T]) isSecret() {}
+
+// secret is implemented by types holding sensitive values, like Secret.
+type secret interface {
+	isSecret()
+	setBytes(v string) bool
+}
+
+var secretType = reflect.TypeOf((*secret)(nil)).Elem() //nolint:gochecknoglobals
+
+func isSecretType(t reflect.Type) bool {
+	return t.Implements(secretType) || reflect.PtrTo(t).Implements(secretType)
+}
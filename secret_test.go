@@ -0,0 +1,99 @@
+package env
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSecret(t *testing.T) {
+	type Config struct {
+		Password Secret[string]   `env:"PASSWORD"`
+		Key      Secret[[]byte]   `env:"KEY,file"`
+		Port     Secret[int]      `env:"PORT" envDefault:"5432"`
+		Tokens   Secret[[]string] `env:"TOKENS"`
+		User     string           `env:"USER"`
+	}
+
+	file := filepath.Join(t.TempDir(), "key")
+	isNoErr(t, os.WriteFile(file, []byte("a,b"), 0o600))
+
+	cfg, err := ParseAsWithOptions[Config](Options{Environment: map[string]string{
+		"PASSWORD": "hunter2",
+		"KEY":      file,
+		"TOKENS":   "foo,bar",
+		"USER":     "admin",
+	}})
+	isNoErr(t, err)
+	isEqual(t, "hunter2", cfg.Password.Reveal())
+	isEqual(t, []byte("a,b"), cfg.Key.Reveal())
+	isEqual(t, 5432, cfg.Port.Reveal())
+	isEqual(t, []string{"foo", "bar"}, cfg.Tokens.Reveal())
+
+	for _, format := range []string{"%v", "%+v", "%#v", "%s", "%q", "%x", "%d"} {
+		t.Run(format, func(t *testing.T) {
+			out := fmt.Sprintf(format, cfg)
+			isFalse(t, containsAny(out, "hunter2", "5432", "foo", "a,b", "6875"))
+		})
+	}
+	isEqual(t, "{Password:[REDACTED] Key:[REDACTED] Port:[REDACTED] Tokens:[REDACTED] User:admin}", fmt.Sprintf("%+v", cfg))
+	isEqual(t, "[REDACTED]", cfg.Password.String())
+	isEqual(t, "[REDACTED]", cfg.Password.GoString())
+
+	b, err := json.Marshal(cfg)
+	isNoErr(t, err)
+	isEqual(t, `{"Password":"[REDACTED]","Key":"[REDACTED]","Port":"[REDACTED]","Tokens":"[REDACTED]","User":"admin"}`, string(b))
+
+	b, err = cfg.Password.MarshalText()
+	isNoErr(t, err)
+	isEqual(t, "[REDACTED]", string(b))
+
+	key := cfg.Key.Reveal()
+	cfg.Key.Wipe()
+	isEqual(t, []byte{0, 0, 0}, key)
+	isEqual(t, []byte(nil), cfg.Key.Reveal())
+
+	cfg.Password.Wipe()
+	isEqual(t, "", cfg.Password.Reveal())
+}
+
+func TestSecretIsSensitive(t *testing.T) {
+	type Config struct {
+		Port Secret[int] `env:"PORT"`
+	}
+
+	_, err := ParseAsWithOptions[Config](Options{Environment: map[string]string{
+		"PORT": "hunter2",
+	}})
+	var perr ParseError
+	isTrue(t, errors.As(err, &perr))
+	isEqual(t, "", perr.Value)
+	isTrue(t, errors.Is(err, strconv.ErrSyntax))
+	isErrorWithMessage(t, err, `env: parse error on field "Port" of type "int": strconv.ParseInt: parsing "[REDACTED]": invalid syntax`)
+	isFalse(t, containsAny(FormatReport(err), "hunter2"))
+
+	params, err := GetFieldParams(&Config{})
+	isNoErr(t, err)
+	isTrue(t, params[0].Sensitive)
+}
+
+func TestNewSecret(t *testing.T) {
+	s := NewSecret("foo")
+	isEqual(t, "foo", s.Reveal())
+	isEqual(t, "[REDACTED]", fmt.Sprint(s))
+	isEqual(t, "[REDACTED]", fmt.Sprint(&s))
+}
+
+func containsAny(s string, subs ...string) bool {
+	for _, sub := range subs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
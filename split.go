@@ -0,0 +1,213 @@
+package env
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+)
+
+// splitItems splits the value of a slice field into its items, honoring
+// the quoted, trimSpace and skipEmpty tag options.
+func splitItems(value, separator string, fieldParams FieldParams) ([]string, error) {
+	raws := splitRawItems(value, separator, fieldParams)
+	items := make([]string, 0, len(raws))
+	for _, raw := range raws {
+		item, err := unquoteItem(raw, fieldParams)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// splitRawItems splits value into its items, honoring the trimSpace and
+// skipEmpty tag options, and keeping the quotes and backslashes of quoted
+// items.
+func splitRawItems(value, separator string, fieldParams FieldParams) []string {
+	var raws []string
+	if fieldParams.Quoted {
+		raws = splitQuoted(value, separator, -1)
+	} else {
+		raws = strings.Split(value, separator)
+	}
+
+	items := raws[:0]
+	for _, raw := range raws {
+		if fieldParams.TrimSpace {
+			raw = strings.TrimSpace(raw)
+		}
+		if fieldParams.SkipEmpty && raw == "" {
+			continue
+		}
+		items = append(items, raw)
+	}
+	return items
+}
+
+// separators are the item and key/value separators of each level of a slice,
+// array or map field, from the outermost to the innermost one.
+type separators struct {
+	items   []string
+	keyVals []string
+}
+
+// newSeparators returns the separators of a slice, array or map field. Nested
+// collections, such as [][]string or map[string][]int, list one separator per
+// level in the envSeparator tag, e.g. `envSeparator:";,"`, and may list one
+// key/value separator per level of maps in the envKeyValSeparator tag.
+func newSeparators(sf reflect.StructField, funcMap map[reflect.Type]ParserFunc) (separators, error) {
+	levels, mapLevels := 0, 0
+	for typ := sf.Type; ; typ = typ.Elem() {
+		levels++
+		if typ.Kind() == reflect.Map {
+			mapLevels++
+		}
+		if !isNestedCollection(typ.Elem(), funcMap) {
+			break
+		}
+	}
+
+	separator := sf.Tag.Get("envSeparator")
+	keyValSeparator := sf.Tag.Get("envKeyValSeparator")
+	if keyValSeparator == "" {
+		keyValSeparator = ":"
+	}
+
+	var seps separators
+	switch {
+	case levels == 1 && separator == "":
+		seps.items = []string{","}
+	case levels == 1:
+		seps.items = []string{separator}
+	case utf8.RuneCountInString(separator) != levels:
+		return seps, fmt.Errorf("envSeparator %q should have one separator per level of %s", separator, sf.Type)
+	default:
+		seps.items = strings.Split(separator, "")
+	}
+
+	if mapLevels > 1 && utf8.RuneCountInString(keyValSeparator) == mapLevels {
+		seps.keyVals = strings.Split(keyValSeparator, "")
+	} else {
+		for i := 0; i < mapLevels; i++ {
+			seps.keyVals = append(seps.keyVals, keyValSeparator)
+		}
+	}
+	return seps, nil
+}
+
+// Separators returns the item and key/value separators of each level of the
+// slice, array or map field sf, from the outermost to the innermost one, as
+// used when parsing it with funcMap. Key/value separators are only listed for
+// the levels that are maps.
+//
+// It returns no separators if sf is not parsed by splitting its value, and an
+// error if its envSeparator tag does not list one separator per level.
+func Separators(sf reflect.StructField, funcMap map[reflect.Type]ParserFunc) (items, keyVals []string, err error) {
+	sf.Type = ValueType(sf.Type)
+	switch sf.Type.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+	default:
+		return nil, nil, nil
+	}
+	if hasElemParser(sf.Type, funcMap) {
+		return nil, nil, nil
+	}
+
+	seps, err := newSeparators(sf, funcMap)
+	if err != nil {
+		return nil, nil, err
+	}
+	return seps.items, seps.keyVals, nil
+}
+
+// next returns the separators of the items of a collection of type typ.
+func (s separators) next(typ reflect.Type) separators {
+	next := separators{items: s.items[1:], keyVals: s.keyVals}
+	if typ.Kind() == reflect.Map {
+		next.keyVals = s.keyVals[1:]
+	}
+	return next
+}
+
+// isNestedCollection reports whether typ is a slice, an array or a map parsed
+// with the separators of its parent collection, rather than with a parser of
+// its own.
+func isNestedCollection(typ reflect.Type, funcMap map[reflect.Type]ParserFunc) bool {
+	if typ.Kind() != reflect.Slice && typ.Kind() != reflect.Array && typ.Kind() != reflect.Map {
+		return false
+	}
+	return !hasElemParser(typ, funcMap)
+}
+
+// splitRawPair splits a raw map item into its key and value, honoring the
+// trimSpace tag option, and keeping the quotes and backslashes of quoted
+// items.
+func splitRawPair(item, keyValSeparator string, fieldParams FieldParams) (string, string, error) {
+	var pair []string
+	if fieldParams.Quoted {
+		pair = splitQuoted(item, keyValSeparator, 2)
+	} else {
+		pair = strings.SplitN(item, keyValSeparator, 2)
+	}
+	if len(pair) != 2 {
+		return "", "", fmt.Errorf(`%q should be in "key%svalue" format`, item, keyValSeparator)
+	}
+
+	if fieldParams.TrimSpace {
+		pair[0], pair[1] = strings.TrimSpace(pair[0]), strings.TrimSpace(pair[1])
+	}
+	return pair[0], pair[1], nil
+}
+
+// splitQuoted splits s around separator, like strings.SplitN, except for the
+// separators between double quotes or escaped with a backslash. Quotes and
+// backslashes are kept, to be removed by unquoteItem.
+func splitQuoted(s, separator string, n int) []string {
+	var result []string
+	inQuotes := false
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\':
+			i++
+		case s[i] == '"':
+			inQuotes = !inQuotes
+		case !inQuotes && separator != "" && strings.HasPrefix(s[i:], separator) && (n < 0 || len(result) < n-1):
+			result = append(result, s[start:i])
+			start = i + len(separator)
+			i = start - 1
+		}
+	}
+	return append(result, s[start:])
+}
+
+// unquoteItem removes the double quotes and backslashes of an item split by
+// splitQuoted. A double quote inside double quotes can be written as "".
+func unquoteItem(s string, fieldParams FieldParams) (string, error) {
+	if !fieldParams.Quoted || !strings.ContainsAny(s, `"\`) {
+		return s, nil
+	}
+
+	var sb strings.Builder
+	inQuotes := false
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s):
+			i++
+			sb.WriteByte(s[i])
+		case s[i] == '"' && inQuotes && i+1 < len(s) && s[i+1] == '"':
+			i++
+			sb.WriteByte('"')
+		case s[i] == '"':
+			inQuotes = !inQuotes
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+	if inQuotes {
+		return "", fmt.Errorf("unterminated quote in %q", s)
+	}
+	return sb.String(), nil
+}
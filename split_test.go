@@ -0,0 +1,159 @@
+package env
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestQuotedSeparators(t *testing.T) {
+	type Config struct {
+		Headers   []string          `env:"HEADERS,quoted"`
+		Patterns  []string          `env:"PATTERNS,quoted" envSeparator:";"`
+		Trimmed   []int             `env:"TRIMMED,trimSpace"`
+		Skipped   []string          `env:"SKIPPED,skipEmpty"`
+		All       []string          `env:"ALL,quoted,trimSpace,skipEmpty"`
+		Labels    map[string]string `env:"LABELS,quoted,trimSpace"`
+		Unquoted  []string          `env:"UNQUOTED"`
+		Delimited []string          `env:"DELIMITED,quoted" envSeparator:"||"`
+	}
+
+	cfg, err := ParseAsWithOptions[Config](Options{Environment: map[string]string{
+		"HEADERS":   `"Accept, Accept-Language",X-Request-Id,"say ""hi""",a\,b,"back\\slash"`,
+		"PATTERNS":  `^a;b$;"c;d"`,
+		"TRIMMED":   " 1 , 2 ,3 ",
+		"SKIPPED":   "a,,b,",
+		"ALL":       ` a , " b " , , "" ,c,`,
+		"LABELS":    ` "a,b" : "c:d" , e\:f:g `,
+		"UNQUOTED":  `"a,b"`,
+		"DELIMITED": `a||"b||c"||d`,
+	}})
+	isNoErr(t, err)
+	isEqual(t, []string{"Accept, Accept-Language", "X-Request-Id", `say "hi"`, "a,b", `back\slash`}, cfg.Headers)
+	isEqual(t, []string{"^a", "b$", "c;d"}, cfg.Patterns)
+	isEqual(t, []int{1, 2, 3}, cfg.Trimmed)
+	isEqual(t, []string{"a", "b"}, cfg.Skipped)
+	isEqual(t, []string{"a", " b ", "", "c"}, cfg.All)
+	isEqual(t, map[string]string{"a,b": "c:d", "e:f": "g"}, cfg.Labels)
+	isEqual(t, []string{`"a`, `b"`}, cfg.Unquoted)
+	isEqual(t, []string{"a", "b||c", "d"}, cfg.Delimited)
+}
+
+func TestQuotedSeparatorsErrors(t *testing.T) {
+	type Config struct {
+		Headers []string          `env:"HEADERS,quoted"`
+		Labels  map[string]string `env:"LABELS,quoted"`
+	}
+
+	_, err := ParseAsWithOptions[Config](Options{Environment: map[string]string{
+		"HEADERS": `a,"b`,
+		"LABELS":  `"a:b"`,
+	}})
+	isErrorWithMessage(t, err, `env: parse error on field "Headers" of type "[]string": unterminated quote in "\"b"; `+
+		`parse error on field "Labels" of type "map[string]string": "\"a:b\"" should be in "key:value" format`)
+}
+
+func TestNestedSeparators(t *testing.T) {
+	type Config struct {
+		Routes  [][]string                   `env:"ROUTES" envSeparator:";,"`
+		Ports   map[string][]int             `env:"PORTS" envSeparator:";,"`
+		Labels  map[string]map[string]string `env:"LABELS" envSeparator:";," envKeyValSeparator:":="`
+		Same    map[string]map[string]int    `env:"SAME" envSeparator:";,"`
+		Quoted  [][]string                   `env:"QUOTED,quoted,trimSpace" envSeparator:"|,"`
+		Grid    [][][]int                    `env:"GRID" envSeparator:";,/"`
+		Default [][]int                      `env:"DEFAULT" envSeparator:";," envDefault:"1,2;3"`
+	}
+
+	cfg, err := ParseAsWithOptions[Config](Options{Environment: map[string]string{
+		"ROUTES": "a,b;c",
+		"PORTS":  "http:80,8080;https:443",
+		"LABELS": "web:tier=front,team=a;db:tier=back",
+		"SAME":   "a:x:1,y:2",
+		"QUOTED": `"a|b", c | "d,e"`,
+		"GRID":   "1/2,3;4",
+	}})
+	isNoErr(t, err)
+	isEqual(t, [][]string{{"a", "b"}, {"c"}}, cfg.Routes)
+	isEqual(t, map[string][]int{"http": {80, 8080}, "https": {443}}, cfg.Ports)
+	isEqual(t, map[string]map[string]string{
+		"web": {"tier": "front", "team": "a"},
+		"db":  {"tier": "back"},
+	}, cfg.Labels)
+	isEqual(t, map[string]map[string]int{"a": {"x": 1, "y": 2}}, cfg.Same)
+	isEqual(t, [][]string{{"a|b", "c"}, {"d,e"}}, cfg.Quoted)
+	isEqual(t, [][][]int{{{1, 2}, {3}}, {{4}}}, cfg.Grid)
+	isEqual(t, [][]int{{1, 2}, {3}}, cfg.Default)
+	isNoErr(t, Check[Config](Options{}))
+}
+
+func TestNestedSeparatorsFuncMap(t *testing.T) {
+	type Config struct {
+		Routes [][]string `env:"ROUTES"`
+	}
+
+	cfg, err := ParseAsWithOptions[Config](Options{
+		Environment: map[string]string{"ROUTES": "a b,c"},
+		FuncMap: map[reflect.Type]ParserFunc{
+			reflect.TypeOf([]string{}): func(v string) (interface{}, error) {
+				return strings.Fields(v), nil
+			},
+		},
+	})
+	isNoErr(t, err)
+	isEqual(t, [][]string{{"a", "b"}, {"c"}}, cfg.Routes)
+}
+
+func TestNestedSeparatorsErrors(t *testing.T) {
+	type Config struct {
+		Routes [][]string       `env:"ROUTES"`
+		Ports  map[string][]int `env:"PORTS" envSeparator:";,"`
+	}
+
+	_, err := ParseAsWithOptions[Config](Options{Environment: map[string]string{
+		"ROUTES": "a,b;c",
+		"PORTS":  "http:80,x",
+	}})
+	isErrorWithMessage(t, err, `env: parse error on field "Routes" of type "[][]string": envSeparator "" should have one separator per level of [][]string; `+
+		`parse error on field "Ports" of type "map[string][]int": strconv.ParseInt: parsing "x": invalid syntax`)
+}
+
+func TestSeparators(t *testing.T) {
+	type Config struct {
+		Tags   []string                     `env:"TAGS"`
+		Routes Optional[[][]string]         `env:"ROUTES" envSeparator:";,"`
+		Labels map[string]map[string]string `env:"LABELS" envSeparator:";," envKeyValSeparator:":="`
+		Ports  map[string][]int             `env:"PORTS" envSeparator:";,"`
+		Host   string                       `env:"HOST"`
+		Fields []string                     `env:"FIELDS"`
+		Wrong  [][]string                   `env:"WRONG"`
+	}
+
+	typ := reflect.TypeOf(Config{})
+	for name, want := range map[string][2][]string{
+		"Tags":   {{","}, nil},
+		"Routes": {{";", ","}, nil},
+		"Labels": {{";", ","}, {":", "="}},
+		"Ports":  {{";", ","}, {":"}},
+		"Host":   {nil, nil},
+	} {
+		sf, _ := typ.FieldByName(name)
+		items, keyVals, err := Separators(sf, nil)
+		isNoErr(t, err)
+		isEqual(t, want[0], items)
+		isEqual(t, want[1], keyVals)
+	}
+
+	sf, _ := typ.FieldByName("Fields")
+	items, keyVals, err := Separators(sf, map[reflect.Type]ParserFunc{
+		reflect.TypeOf([]string{}): func(v string) (interface{}, error) {
+			return strings.Fields(v), nil
+		},
+	})
+	isNoErr(t, err)
+	isEqual(t, []string(nil), items)
+	isEqual(t, []string(nil), keyVals)
+
+	sf, _ = typ.FieldByName("Wrong")
+	_, _, err = Separators(sf, nil)
+	isErrorWithMessage(t, err, `envSeparator "" should have one separator per level of [][]string`)
+}
@@ -0,0 +1,85 @@
+package env
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// RegisterVariant registers T as the concrete type to use in fields of the
+// interface type I when their variable is set to name.
+//
+// The variables of T are read with the prefix of the field followed by name,
+// converted to upper case. For example, given:
+//
+//	type Config struct {
+//		Storage Storage `env:"STORAGE_KIND" envPrefix:"STORAGE_"`
+//	}
+//	env.RegisterVariant[Storage, S3Config](&opts, "s3")
+//
+// STORAGE_KIND=s3 makes Storage an S3Config, parsed from the STORAGE_S3_*
+// variables.
+//
+// It panics if I is not an interface, if T is not a struct, or if neither T
+// nor *T implement it.
+func RegisterVariant[I, T any](opts *Options, name string) {
+	iface := reflect.TypeOf((*I)(nil)).Elem()
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if iface.Kind() != reflect.Interface {
+		panic(fmt.Sprintf("env: %s is not an interface", iface))
+	}
+	if typ.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("env: %s is not a struct", typ))
+	}
+	if !typ.Implements(iface) && !reflect.PtrTo(typ).Implements(iface) {
+		panic(fmt.Sprintf("env: %s does not implement %s", typ, iface))
+	}
+
+	if opts.Variants == nil {
+		opts.Variants = map[reflect.Type]map[string]reflect.Type{}
+	}
+	if opts.Variants[iface] == nil {
+		opts.Variants[iface] = map[string]reflect.Type{}
+	}
+	opts.Variants[iface][name] = typ
+}
+
+// setVariant sets field to a new value of the concrete type registered for
+// value, parsing its own variables.
+func setVariant(
+	field reflect.Value,
+	sf reflect.StructField,
+	key, value string,
+	variants map[string]reflect.Type,
+	opts Options,
+) error {
+	typ, ok := variants[value]
+	if !ok {
+		return newUnknownVariantError(key, fieldPath(sf, opts), value, variantNames(variants))
+	}
+
+	variantOpts := optionsWithEnvPrefix(sf, opts)
+	variantOpts.Prefix += RelaxedKeyMatcher(value) + string(underscore)
+
+	ptr := reflect.New(typ)
+	if err := doParse(ptr.Elem(), setField, variantOpts); err != nil {
+		return err
+	}
+
+	if typ.Implements(field.Type()) {
+		field.Set(ptr.Elem())
+	} else {
+		field.Set(ptr)
+	}
+	return nil
+}
+
+// variantNames returns the sorted names of variants.
+func variantNames(variants map[string]reflect.Type) []string {
+	names := make([]string, 0, len(variants))
+	for name := range variants {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,140 @@
+package env
+
+import (
+	"errors"
+	"testing"
+)
+
+type storage interface {
+	Name() string
+}
+
+type s3Storage struct {
+	Bucket string `env:"BUCKET,required"`
+	Region string `env:"REGION" envDefault:"us-east-1"`
+}
+
+func (s s3Storage) Name() string { return "s3" }
+
+type gcsStorage struct {
+	Bucket string `env:"BUCKET,required"`
+}
+
+func (s *gcsStorage) Name() string { return "gcs" }
+
+type memStorage string
+
+func (s memStorage) Name() string { return string(s) }
+
+func TestVariant(t *testing.T) {
+	type Config struct {
+		Storage storage `env:"STORAGE_KIND" envPrefix:"STORAGE_"`
+		Backup  storage `env:"BACKUP_KIND" envPrefix:"BACKUP_" envDefault:"google-cloud"`
+	}
+
+	var opts Options
+	RegisterVariant[storage, s3Storage](&opts, "s3")
+	RegisterVariant[storage, gcsStorage](&opts, "gcs")
+	RegisterVariant[storage, gcsStorage](&opts, "google-cloud")
+
+	t.Run("s3", func(t *testing.T) {
+		opts := opts
+		opts.Environment = map[string]string{
+			"STORAGE_KIND":               "s3",
+			"STORAGE_S3_BUCKET":          "foo",
+			"STORAGE_GCS_BUCKET":         "bar",
+			"BACKUP_GOOGLE_CLOUD_BUCKET": "backup",
+		}
+		cfg, err := ParseAsWithOptions[Config](opts)
+		isNoErr(t, err)
+		isEqual(t, s3Storage{Bucket: "foo", Region: "us-east-1"}, cfg.Storage)
+		isEqual(t, &gcsStorage{Bucket: "backup"}, cfg.Backup)
+	})
+
+	t.Run("gcs", func(t *testing.T) {
+		opts := opts
+		opts.Environment = map[string]string{
+			"STORAGE_KIND":               "gcs",
+			"STORAGE_GCS_BUCKET":         "bar",
+			"BACKUP_GOOGLE_CLOUD_BUCKET": "backup",
+		}
+		cfg, err := ParseAsWithOptions[Config](opts)
+		isNoErr(t, err)
+		isEqual(t, &gcsStorage{Bucket: "bar"}, cfg.Storage)
+		isEqual(t, "gcs", cfg.Storage.Name())
+	})
+
+	t.Run("unset", func(t *testing.T) {
+		opts := opts
+		opts.Environment = map[string]string{
+			"BACKUP_GOOGLE_CLOUD_BUCKET": "backup",
+		}
+		cfg, err := ParseAsWithOptions[Config](opts)
+		isNoErr(t, err)
+		isTrue(t, cfg.Storage == nil)
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		opts := opts
+		opts.Prefix = "APP_"
+		opts.Environment = map[string]string{
+			"APP_STORAGE_KIND":               "azure",
+			"APP_BACKUP_GOOGLE_CLOUD_BUCKET": "backup",
+		}
+		_, err := ParseAsWithOptions[Config](opts)
+		isErrorWithMessage(t, err, `env: environment variable "APP_STORAGE_KIND" has unknown variant "azure", expected one of ["gcs" "google-cloud" "s3"]`)
+		var verr UnknownVariantError
+		isTrue(t, errors.As(err, &verr))
+		isEqual(t, "Storage", verr.Path)
+		isEqual(t, "azure", verr.Value)
+	})
+
+	t.Run("variant errors", func(t *testing.T) {
+		opts := opts
+		opts.Environment = map[string]string{
+			"STORAGE_KIND": "s3",
+		}
+		_, err := ParseAsWithOptions[Config](opts)
+		isEqual(t, AggregateError{Errors: []error{
+			VarIsNotSetError{Key: "STORAGE_S3_BUCKET", Path: "Storage.Bucket"},
+			VarIsNotSetError{Key: "BACKUP_GOOGLE_CLOUD_BUCKET", Path: "Backup.Bucket"},
+		}}, err)
+	})
+
+	t.Run("not registered", func(t *testing.T) {
+		_, err := ParseAsWithOptions[Config](Options{Environment: map[string]string{
+			"STORAGE_KIND": "s3",
+		}})
+		isTrue(t, errors.Is(err, NoParserError{}))
+	})
+}
+
+func TestRegisterVariantPanics(t *testing.T) {
+	t.Run("not an interface", func(t *testing.T) {
+		defer func() {
+			isEqual(t, "env: env.s3Storage is not an interface", recover())
+		}()
+		RegisterVariant[s3Storage, s3Storage](&Options{}, "s3")
+	})
+
+	t.Run("not a struct", func(t *testing.T) {
+		defer func() {
+			isEqual(t, "env: env.memStorage is not a struct", recover())
+		}()
+		RegisterVariant[storage, memStorage](&Options{}, "mem")
+	})
+
+	t.Run("pointer", func(t *testing.T) {
+		defer func() {
+			isEqual(t, "env: *env.s3Storage is not a struct", recover())
+		}()
+		RegisterVariant[storage, *s3Storage](&Options{}, "s3")
+	})
+
+	t.Run("does not implement", func(t *testing.T) {
+		defer func() {
+			isEqual(t, "env: env.Options does not implement env.storage", recover())
+		}()
+		RegisterVariant[storage, Options](&Options{}, "s3")
+	})
+}